@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"secretsnap/internal/api"
+	"secretsnap/internal/config"
 	"secretsnap/internal/crypto"
 )
 
@@ -265,7 +266,7 @@ func cleanupTestData(t *testing.T, data *TestData) {
 
 // ensureNoToken ensures there's no token file for license enforcement tests
 func ensureNoToken(t *testing.T) {
-	tokenFile := filepath.Join(os.Getenv("HOME"), ".secretsnap", "token")
+	tokenFile := config.GetTokenPath()
 	if _, err := os.Stat(tokenFile); err == nil {
 		if err := os.Remove(tokenFile); err != nil {
 			t.Logf("Warning: failed to remove token file: %v", err)
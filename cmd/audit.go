@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"secretsnap/internal/api"
@@ -23,18 +22,18 @@ var auditCmd = &cobra.Command{
 	Long:  `View recent audit logs for a project to track access and changes.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load project config and token
-		projectConfig, err := config.LoadProjectConfig()
+		projectConfig, err := readProjectConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+			return err
 		}
 
 		token, err := config.LoadToken()
 		if err != nil {
-			return fmt.Errorf("failed to load token: %v", err)
+			return fmt.Errorf("failed to load token: %w", err)
 		}
 
 		if token == "" {
-			return fmt.Errorf("not logged in. Run 'secretsnap login --license <KEY>' first")
+			return fmt.Errorf("%w. Run 'secretsnap login --license <KEY>' first", config.ErrNotLoggedIn)
 		}
 
 		// Use project from config if not specified
@@ -52,15 +51,22 @@ var auditCmd = &cobra.Command{
 		// Get audit logs
 		logs, err := client.GetAuditLogs(auditProject, auditLimit)
 		if err != nil {
-			return fmt.Errorf("failed to get audit logs: %v", err)
+			return fmt.Errorf("failed to get audit logs: %w", err)
+		}
+
+		if jsonOutput() {
+			if logs == nil {
+				logs = []api.AuditLog{}
+			}
+			return writeJSON(auditResult{Project: auditProject, Logs: logs})
 		}
 
 		if len(logs) == 0 {
-			fmt.Println("No audit logs found.")
+			fmt.Fprintln(stdout, "No audit logs found.")
 			return nil
 		}
 
-		fmt.Printf("📋 Audit logs for project %s:\n\n", projectConfig.ProjectName)
+		fmt.Fprintf(stdout, "📋 Audit logs for project %s:\n\n", projectConfig.ProjectName)
 		for _, log := range logs {
 			// Parse timestamp
 			t, err := time.Parse(time.RFC3339, log.CreatedAt)
@@ -68,18 +74,18 @@ var auditCmd = &cobra.Command{
 				t = time.Now() // Fallback
 			}
 
-			fmt.Printf("🕐 %s\n", t.Format("2006-01-02 15:04:05"))
-			fmt.Printf("📝 Action: %s\n", log.Action)
+			fmt.Fprintf(stdout, "🕐 %s\n", t.Format("2006-01-02 15:04:05"))
+			fmt.Fprintf(stdout, "📝 Action: %s\n", log.Action)
 			if len(log.Details) > 0 {
-				fmt.Printf("📄 Details: %v\n", log.Details)
+				fmt.Fprintf(stdout, "📄 Details: %v\n", log.Details)
 			}
-			fmt.Println()
+			fmt.Fprintln(stdout)
 		}
 
 		// Show feature-specific upsell for audit logs
 		if err := utils.ShowFeatureUpsell("audit"); err != nil {
 			// Don't fail the command if upsell fails
-			fmt.Fprintf(os.Stderr, "Warning: failed to show upsell: %v\n", err)
+			fmt.Fprintf(stderr, "Warning: failed to show upsell: %v\n", err)
 		}
 
 		return nil
@@ -13,23 +13,33 @@ import (
 )
 
 var (
-	bundleOutFile  string
-	bundlePass     string
-	bundlePassFile string
-	bundlePassMode bool
-	bundlePush     bool
-	bundleProject  string
-	bundleForce    bool
-	bundleExpire   string
-	bundleVersion  int
+	bundleOutFile string
+	bundlePass    passFlags
+	bundlePush    bool
+	bundleProject string
+	bundleForce   bool
+	bundleExpire  string
+	bundleVersion int
 )
 
 var bundleCmd = &cobra.Command{
 	Use:   "bundle [path-to-.env]",
 	Short: "Encrypt a .env file into a bundle",
-	Long:  `Encrypt a .env file using age encryption. Supports local mode (cached key), passphrase mode, and cloud mode.`,
-	Args:  cobra.ExactArgs(1),
+	Long:  `Encrypt a .env file using age encryption. Supports local mode (cached key), passphrase mode, and cloud mode. With --all, each workspace project's .env (or the given path, relative to the project) is bundled.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if workspaceAll {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if workspaceAll {
+			if len(args) == 0 {
+				args = []string{".env"}
+			}
+			return runWorkspace(cmd, args, &bundlePass)
+		}
+
 		inputFile := args[0]
 
 		// Validate input file exists and is not empty
@@ -39,7 +49,7 @@ var bundleCmd = &cobra.Command{
 
 		data, err := os.ReadFile(inputFile)
 		if err != nil {
-			return fmt.Errorf("failed to read input file: %v", err)
+			return fmt.Errorf("failed to read input file: %w", err)
 		}
 
 		if len(data) == 0 {
@@ -47,27 +57,40 @@ var bundleCmd = &cobra.Command{
 		}
 
 		// Load project config
-		projectConfig, err := config.LoadProjectConfig()
+		projectConfig, err := loadProjectConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+			return err
 		}
 
 		// Determine mode based on flags and config
-		mode := determineMode(projectConfig, bundlePass, bundlePassFile, bundlePassMode, bundlePush)
+		src := bundlePass.PassphraseSource
+		if !src.Explicit() {
+			src.File = projectConfig.PassFile
+		}
+		mode := determineMode(projectConfig, src.Pass, src.File, bundlePass.mode || src.Explicit(), bundlePush)
+
+		outFile := bundleOutFile
+		if outFile == "" {
+			outFile = defaultBundlePath(projectConfig)
+		}
 
 		var encryptedData []byte
 
 		switch mode {
 		case "passphrase":
 			// Passphrase mode
-			passphrase, err := utils.GetPassphrase(bundlePass, bundlePassFile)
+			workFactor, err := projectConfig.Passphrase.ScryptWorkFactor()
 			if err != nil {
-				return fmt.Errorf("failed to get passphrase: %v", err)
+				return err
+			}
+			passphrase, err := utils.GetNewPassphrase(src, projectConfig.Passphrase)
+			if err != nil {
+				return fmt.Errorf("failed to get passphrase: %w", err)
 			}
 
-			encryptedData, err = crypto.EncryptWithPassphrase(data, passphrase)
+			encryptedData, err = crypto.EncryptWithPassphrase(data, passphrase, workFactor)
 			if err != nil {
-				return fmt.Errorf("failed to encrypt: %v", err)
+				return fmt.Errorf("failed to encrypt: %w", err)
 			}
 
 		case "cloud":
@@ -79,16 +102,16 @@ var bundleCmd = &cobra.Command{
 			// Check if user is logged in
 			token, err := config.LoadToken()
 			if err != nil {
-				return fmt.Errorf("failed to load token: %v", err)
+				return fmt.Errorf("failed to load token: %w", err)
 			}
 			if token == "" {
-				return fmt.Errorf("cloud sync is Pro. Run `secretsnap login --license …` or use local mode (no `--push`)")
+				return fmt.Errorf("%w: cloud sync is Pro. Run `secretsnap login --license …` or use local mode (no `--push`)", config.ErrNotLoggedIn)
 			}
 
 			// Load project config to get project ID
-			projectConfig, err := config.LoadProjectConfig()
+			projectConfig, err := loadProjectConfig()
 			if err != nil {
-				return fmt.Errorf("failed to load project config: %v", err)
+				return err
 			}
 
 			// Use project from flag or config
@@ -104,85 +127,88 @@ var bundleCmd = &cobra.Command{
 			// Generate fresh data key for this bundle
 			dataKey, err := crypto.GenerateDataKey()
 			if err != nil {
-				return fmt.Errorf("failed to generate data key: %v", err)
+				return fmt.Errorf("failed to generate data key: %w", err)
 			}
 
 			// Encrypt data with the data key
 			encryptedData, err = crypto.EncryptWithKey(data, dataKey)
 			if err != nil {
-				return fmt.Errorf("failed to encrypt: %v", err)
+				return fmt.Errorf("failed to encrypt: %w", err)
 			}
 
 			// Create API client
 			client := api.NewClient(utils.GetAPIURL(), token)
 
 			// Step 1: Get upload URL from API
-			fmt.Printf("📤 Starting cloud upload...\n")
+			humanf("📤 Starting cloud upload...\n")
 			pushResp, err := client.BundlePush(projectID, len(encryptedData))
 			if err != nil {
-				return fmt.Errorf("failed to get upload URL: %v", err)
+				return fmt.Errorf("failed to get upload URL: %w", err)
 			}
 
 			// Step 2: Upload encrypted data to API server
-			fmt.Printf("☁️ Uploading to cloud storage...\n")
+			humanf("☁️ Uploading to cloud storage...\n")
 			if err := client.UploadToAPI(pushResp.UploadURL, encryptedData); err != nil {
-				return fmt.Errorf("failed to upload to cloud: %v", err)
+				return fmt.Errorf("failed to upload to cloud: %w", err)
 			}
 
 			// Step 3: Finalize bundle (API will handle KMS wrapping)
-			fmt.Printf("🔐 Securing with KMS...\n")
+			humanf("🔐 Securing with KMS...\n")
 			if err := client.BundleFinalize(pushResp.BundleID, pushResp.S3Key, dataKey); err != nil {
-				return fmt.Errorf("failed to finalize bundle: %v", err)
+				return fmt.Errorf("failed to finalize bundle: %w", err)
 			}
 
-			fmt.Printf("✅ Successfully pushed to cloud!\n")
-			fmt.Printf("📦 Bundle ID: %s\n", pushResp.BundleID)
-			fmt.Printf("📁 Project: %s\n", projectConfig.ProjectName)
-			fmt.Printf("🔢 Version: v%d\n", pushResp.Version)
+			humanf("✅ Successfully pushed to cloud!\n")
+			humanf("📦 Bundle ID: %s\n", pushResp.BundleID)
+			humanf("📁 Project: %s\n", projectConfig.ProjectName)
+			humanf("🔢 Version: v%d\n", pushResp.Version)
+			recordRemoteVersion(projectID, pushResp.Version)
 
 			// Also save local copy if requested
-			if bundleOutFile != "secrets.envsnap" {
+			if bundleOutFile != "" {
 				if err := os.WriteFile(bundleOutFile, encryptedData, 0644); err != nil {
-					return fmt.Errorf("failed to write local copy: %v", err)
+					return fmt.Errorf("failed to write local copy: %w", err)
 				}
-				fmt.Printf("💾 Local copy saved to: %s\n", bundleOutFile)
+				humanf("💾 Local copy saved to: %s\n", bundleOutFile)
 			}
 
+			if jsonOutput() {
+				return writeJSON(bundleResult{
+					Input:    inputFile,
+					Output:   bundleOutFile,
+					Mode:     mode,
+					Project:  projectConfig.ProjectName,
+					BundleID: pushResp.BundleID,
+					Version:  pushResp.Version,
+				})
+			}
 			return nil
 
 		default:
 			// Local mode (default)
-			projectKey, err := config.GetProjectKey(projectConfig.ProjectName)
-			if err != nil {
-				return fmt.Errorf("no local project key found for '%s'. Fix:\n"+
-					"• On teammate's machine: `secretsnap key export --project %s`\n"+
-					"• Or use passphrase: `--pass`\n"+
-					"• Or use paid pull: `secretsnap login` then `secretsnap pull`",
-					projectConfig.ProjectName, projectConfig.ProjectName)
-			}
-
-			keyBytes, err := crypto.KeyFromBase64(projectKey.KeyB64)
+			keyBytes, err := loadProjectKeyBytes(projectConfig.ProjectName)
 			if err != nil {
-				return fmt.Errorf("failed to decode project key: %v", err)
+				return err
 			}
 
 			encryptedData, err = crypto.EncryptWithKey(data, keyBytes)
 			if err != nil {
-				return fmt.Errorf("failed to encrypt: %v", err)
+				return fmt.Errorf("failed to encrypt: %w", err)
 			}
 		}
 
 		// Check if output file exists and handle --force
-		if _, err := os.Stat(bundleOutFile); err == nil && !bundleForce {
-			return fmt.Errorf("refusing to overwrite %s. Use `--force`", bundleOutFile)
+		if _, err := os.Stat(outFile); err == nil && !bundleForce {
+			return fmt.Errorf("refusing to overwrite %s. Use `--force`", outFile)
 		}
 
 		// Write output file
-		if err := os.WriteFile(bundleOutFile, encryptedData, 0644); err != nil {
-			return fmt.Errorf("failed to write output file: %v", err)
+		if err := os.WriteFile(outFile, encryptedData, 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
 		}
 
-		fmt.Printf("✅ Encrypted %s to %s\n", inputFile, bundleOutFile)
+		humanf("✅ Encrypted %s to %s\n", inputFile, outFile)
+		recordSync(outFile, data)
 
 		// Track usage and show upsell for free users
 		if mode == "local" || mode == "passphrase" {
@@ -190,20 +216,23 @@ var bundleCmd = &cobra.Command{
 			utils.ShowContextualUpsell("bundle")
 		}
 
+		if jsonOutput() {
+			return writeJSON(bundleResult{Input: inputFile, Output: outFile, Mode: mode})
+		}
+
 		return nil
 	},
 }
 
 func init() {
-	bundleCmd.Flags().StringVarP(&bundleOutFile, "out", "o", "secrets.envsnap", "Output file path")
-	bundleCmd.Flags().StringVarP(&bundlePass, "pass", "p", "", "Passphrase (prompted if not provided)")
-	bundleCmd.Flags().StringVarP(&bundlePassFile, "pass-file", "", "", "Read passphrase from file")
-	bundleCmd.Flags().BoolVarP(&bundlePassMode, "pass-mode", "", false, "Use passphrase mode (prompt for passphrase)")
+	bundleCmd.Flags().StringVarP(&bundleOutFile, "out", "o", "", "Output file path (defaults to the project's bundle_path)")
+	addPassFlags(bundleCmd, &bundlePass)
 	bundleCmd.Flags().BoolVarP(&bundlePush, "push", "", false, "Push to cloud (cloud mode only)")
 	bundleCmd.Flags().StringVarP(&bundleProject, "project", "", "", "Project ID or name (cloud mode only)")
 	bundleCmd.Flags().BoolVarP(&bundleForce, "force", "f", false, "Overwrite output file if it exists")
 	bundleCmd.Flags().StringVarP(&bundleExpire, "expire", "", "", "Expiration time (e.g., 24h)")
 	bundleCmd.Flags().IntVarP(&bundleVersion, "version", "", 0, "Version number")
+	addWorkspaceFlags(bundleCmd)
 }
 
 // determineMode determines the encryption mode based on flags and config
@@ -214,7 +243,7 @@ func determineMode(projectConfig *config.ProjectConfig, pass, passFile string, p
 	}
 
 	// Passphrase mode is checked before local (fallback when explicitly requested)
-	if pass != "" || passFile != "" || passMode {
+	if pass != "" || passFile != "" || passMode || (projectConfig != nil && projectConfig.Mode == "passphrase") {
 		return "passphrase"
 	}
 
@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/utils"
 )
 
 func TestDetermineMode(t *testing.T) {
@@ -406,3 +412,55 @@ func BenchmarkDetermineMode(b *testing.B) {
 		})
 	}
 }
+
+func TestBundleExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() {
+		config.SetGlobalDir("")
+		config.SetProjectDir("")
+	})
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv(config.IdentityEnv, "")
+	if err := config.SetGlobalDir(filepath.Join(dir, "home")); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SetProjectDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, ".secretsnap.json"), []byte(`{"project_name": "app", "mode": "local"}`), 0600)
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(envFile, []byte("A=1\n"), 0600)
+
+	// Like unbundle and run, a missing key exits with its own code
+	if got := exitCode(bundleCmd.RunE(bundleCmd, []string{envFile})); got != exitNoProjectKey {
+		t.Errorf("bundle without a key exit code = %d, want %d", got, exitNoProjectKey)
+	}
+
+	key, _ := crypto.GenerateProjectKey()
+	if err := config.SaveProjectKey("app", &config.ProjectKey{KeyB64: crypto.KeyToBase64(key), KeyID: "id"}); err != nil {
+		t.Fatal(err)
+	}
+	master, _ := crypto.GenerateIdentity()
+	if err := config.EncryptKeystore(master.Recipient()); err != nil {
+		t.Fatal(err)
+	}
+	if got := exitCode(bundleCmd.RunE(bundleCmd, []string{envFile})); got != exitLocked {
+		t.Errorf("bundle with a locked key store exit code = %d, want %d", got, exitLocked)
+	}
+}
+
+func TestDecryptBundleRefusesConflicts(t *testing.T) {
+	src := utils.PassphraseSource{Pass: "correct horse battery"}
+	conflicted := "A=1\n" + dotenv.ConflictOurs + "\nB=ours\n" + dotenv.ConflictSep + "\nB=theirs\n" + dotenv.ConflictTheirs + "\n"
+
+	for content, wantErr := range map[string]bool{"A=1\nB=2\n": false, conflicted: true} {
+		encryptedData, err := crypto.EncryptWithPassphrase([]byte(content), src.Pass, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = decryptBundle(encryptedData, &config.ProjectConfig{Mode: "passphrase"}, src, true)
+		if got := errors.Is(err, dotenv.ErrConflicts); got != wantErr {
+			t.Errorf("decryptBundle(%q) error = %v, want ErrConflicts: %v", content, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/utils"
+)
+
+// readBundleFile reads an encrypted bundle and rejects missing or empty files
+func readBundleFile(path string) ([]byte, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, withExitCode(exitNotFound, fmt.Errorf("input file '%s' does not exist", path))
+	}
+
+	encryptedData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+
+	if len(encryptedData) == 0 {
+		return nil, fmt.Errorf("input file '%s' is empty", path)
+	}
+
+	return encryptedData, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, so a crash never leaves a truncated file behind. The file
+// has permissions perm from the start.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if err := temp.Chmod(perm); err != nil {
+		temp.Close()
+		return err
+	}
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// defaultBundlePath returns the project's configured bundle path, relative
+// to the current directory
+func defaultBundlePath(projectConfig *config.ProjectConfig) string {
+	if projectConfig.BundlePath != "" {
+		return projectConfig.Path(projectConfig.BundlePath)
+	}
+	return projectConfig.Path("secrets.envsnap")
+}
+
+// bundleKey is the resolved secret for a local bundle: either the cached
+// project key or a passphrase. Resolving it once lets a command decrypt and
+// re-encrypt the same bundle without prompting twice.
+type bundleKey struct {
+	mode       string // "local" or "passphrase"
+	key        []byte
+	passphrase string
+	workFactor int // scrypt cost when re-encrypting with the passphrase
+}
+
+// resolveBundleKey picks the passphrase when one of the passphrase flags is
+// set or the environment uses passphrase mode, and the cached project key
+// otherwise
+func resolveBundleKey(projectConfig *config.ProjectConfig, src utils.PassphraseSource, passMode bool) (*bundleKey, error) {
+	if !src.Explicit() {
+		src.File = projectConfig.PassFile
+	}
+	mode := determineUnbundleMode(src, passMode || projectConfig.Mode == "passphrase")
+
+	if mode == "passphrase" {
+		workFactor, err := projectConfig.Passphrase.ScryptWorkFactor()
+		if err != nil {
+			return nil, err
+		}
+		passphrase, err := utils.GetPassphrase(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get passphrase: %w", err)
+		}
+		return &bundleKey{mode: mode, passphrase: passphrase, workFactor: workFactor}, nil
+	}
+
+	keyBytes, err := loadProjectKeyBytes(projectConfig.ProjectName)
+	if err != nil {
+		return nil, err
+	}
+	return &bundleKey{mode: mode, key: keyBytes}, nil
+}
+
+// decrypt decrypts bundle data with the resolved key
+func (k *bundleKey) decrypt(encryptedData []byte) ([]byte, error) {
+	var decryptedData []byte
+	var err error
+	if k.mode == "passphrase" {
+		decryptedData, err = crypto.DecryptWithPassphrase(encryptedData, k.passphrase)
+	} else {
+		decryptedData, err = crypto.DecryptWithKey(encryptedData, k.key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return decryptedData, nil
+}
+
+// decryptResolved decrypts bundle data like decrypt, and refuses a bundle
+// that still has merge conflicts
+func (k *bundleKey) decryptResolved(encryptedData []byte) ([]byte, error) {
+	decryptedData, err := k.decrypt(encryptedData)
+	if err != nil {
+		return nil, err
+	}
+	if dotenv.Parse(decryptedData).HasConflicts() {
+		return nil, fmt.Errorf("the bundle has %w. Fix: `secretsnap edit`", dotenv.ErrConflicts)
+	}
+	return decryptedData, nil
+}
+
+// encrypt encrypts plaintext with the resolved key
+func (k *bundleKey) encrypt(data []byte) ([]byte, error) {
+	var encryptedData []byte
+	var err error
+	if k.mode == "passphrase" {
+		encryptedData, err = crypto.EncryptWithPassphrase(data, k.passphrase, k.workFactor)
+	} else {
+		encryptedData, err = crypto.EncryptWithKey(data, k.key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	return encryptedData, nil
+}
+
+// decryptBundle decrypts bundle data with a passphrase when one of the
+// passphrase flags is set, and with the cached project key otherwise.
+// It returns the plaintext and the mode that was used.
+func decryptBundle(encryptedData []byte, projectConfig *config.ProjectConfig, src utils.PassphraseSource, passMode bool) ([]byte, string, error) {
+	key, err := resolveBundleKey(projectConfig, src, passMode)
+	if err != nil {
+		return nil, determineUnbundleMode(src, passMode), err
+	}
+
+	decryptedData, err := key.decryptResolved(encryptedData)
+	if err != nil {
+		return nil, key.mode, err
+	}
+	return decryptedData, key.mode, nil
+}
+
+// loadProjectKeyBytes returns the cached key for a project, with a fix hint
+// when the key is missing
+func loadProjectKeyBytes(projectName string) ([]byte, error) {
+	projectKey, err := config.GetProjectKey(projectName)
+	if errors.Is(err, config.ErrNoProjectKey) {
+		return nil, fmt.Errorf("%w for '%s'. Fix:\n"+
+			"• On teammate's machine: `secretsnap key export --project %s`\n"+
+			"• Or use passphrase: `--pass`\n"+
+			"• Or use paid pull: `secretsnap login` then `secretsnap pull`",
+			config.ErrNoProjectKey, projectName, projectName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes, err := crypto.KeyFromBase64(projectKey.KeyB64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode project key: %w", err)
+	}
+
+	return keyBytes, nil
+}
@@ -1,17 +1,73 @@
 package cmd
 
 import (
+	"fmt"
+
+	"secretsnap/internal/config"
+
 	"github.com/spf13/cobra"
 )
 
 // InitCommands registers all commands with the root command
 func InitCommands(rootCmd *cobra.Command) {
+	// Global flags
+	rootCmd.PersistentFlags().StringVarP(&envName, "env", "e", "", "Environment from .secretsnap.json (or SECRETSNAP_ENV)")
+	rootCmd.PersistentFlags().StringVarP(&projectDirFlag, "project-dir", "C", "", "Project directory (default: discovered from the current directory)")
+	rootCmd.PersistentFlags().StringVarP(&configDirFlag, "config-dir", "", "", "Directory for keys, token and usage (or SECRETSNAP_HOME)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "", "text", "Output format (text|json)")
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Only print command data: no progress messages or upgrade banners (or SECRETSNAP_QUIET)")
+	rootCmd.PersistentFlags().BoolVarP(&plainFlag, "plain", "", false, "Plain text without emoji (or NO_COLOR)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if outputFormat != "text" && outputFormat != "json" {
+			return withExitCode(exitUsage, fmt.Errorf("output must be 'text' or 'json', got '%s'", outputFormat))
+		}
+		// Flags and arguments are valid; later errors are not usage errors
+		cmd.SilenceUsage = true
+		if jsonOutput() {
+			// Commands with their own --format follow --output unless set
+			if format := cmd.Flags().Lookup("format"); format != nil && !format.Changed {
+				format.Value.Set("json")
+			}
+		}
+
+		if err := config.SetGlobalDir(configDirFlag); err != nil {
+			return err
+		}
+		configureOutput()
+		if migratesLegacyDir(cmd) {
+			if legacy, err := config.MigrateLegacyDir(); err != nil {
+				fmt.Fprintf(stderr, "⚠️  Could not move the old secretsnap directory: %v\n", err)
+			} else if legacy != "" && !quiet {
+				fmt.Fprintf(stderr, "📦 Moved %s to %s\n", legacy, config.GetConfigDir())
+			}
+		}
+		if err := config.SetProjectDir(projectDirFlag); err != nil {
+			return err
+		}
+		return nil
+	}
+
 	// Free commands
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(bundleCmd)
 	rootCmd.AddCommand(unbundleCmd)
 	rootCmd.AddCommand(runCmd)
-	rootCmd.AddCommand(keyExportCmd)
+	rootCmd.AddCommand(keyCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(exampleCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(setCmd)
+	rootCmd.AddCommand(staleCmd)
+	rootCmd.AddCommand(renderCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(gitCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(scanCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(verifyCmd)
 
 	// Paid commands
 	rootCmd.AddCommand(loginCmd)
@@ -19,4 +75,17 @@ func InitCommands(rootCmd *cobra.Command) {
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(shareCmd)
 	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(settingsCmd)
+	rootCmd.AddCommand(rekeyCmd)
+	rootCmd.AddCommand(keystoreCmd)
+
+	// Errors are printed once by Execute, with a specific exit code
+	rootCmd.SilenceErrors = true
+	usageErrors(rootCmd)
+}
+
+// migratesLegacyDir reports whether cmd may move the files of the old
+// ~/.secretsnap directory. Diagnostics only read, so they leave it alone.
+func migratesLegacyDir(cmd *cobra.Command) bool {
+	return cmd != doctorCmd && cmd != lintCmd
 }
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareProjects  map[string]string
+	comparePasses    map[string]string
+	comparePassFiles map[string]string
+	comparePassModes []string
+	compareFormat    string
+)
+
+// compareRow is one key in the comparison matrix
+type compareRow struct {
+	Key     string `json:"key"`
+	Present []bool `json:"present"` // aligned with compareReport.Bundles
+	Values  string `json:"values"`  // "same", "different" or "single"
+}
+
+// compareReport is the machine-readable output of `compare --format json`
+type compareReport struct {
+	Bundles []string     `json:"bundles"`
+	Keys    []compareRow `json:"keys"`
+	InSync  bool         `json:"in_sync"`
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <bundle> <bundle> [bundle...]",
+	Short: "Compare key sets across bundles without revealing values",
+	Long: `Decrypt several bundles in memory and print which keys are present in each.
+Values are compared through keyed hashes and reported only as same or different.
+
+Each bundle is decrypted with the current project's cached key unless told
+otherwise:
+
+  --project prod.envsnap=myapp-prod     use another project's cached key
+  --pass-file prod.envsnap=prod.pass    use a passphrase from a file
+  --pass-mode prod.envsnap              prompt for that bundle's passphrase
+
+The command exits non-zero when the key sets differ.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if compareFormat != "text" && compareFormat != "json" {
+			return fmt.Errorf("format must be 'text' or 'json', got '%s'", compareFormat)
+		}
+
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		hasher, err := crypto.NewValueHasher()
+		if err != nil {
+			return err
+		}
+
+		passModes := make(map[string]bool)
+		for _, path := range comparePassModes {
+			passModes[path] = true
+		}
+
+		// Hash every value as soon as the bundle is decrypted so no
+		// plaintext outlives the loop iteration
+		hashes := make([]map[string]string, len(args))
+		for i, path := range args {
+			encryptedData, err := readBundleFile(path)
+			if err != nil {
+				return err
+			}
+
+			bundleConfig := *projectConfig
+			if project, ok := compareProjects[path]; ok {
+				bundleConfig.ProjectName = project
+			}
+
+			if passModes[path] {
+				fmt.Fprintf(stderr, "🔐 Passphrase for %s\n", path)
+			}
+
+			decryptedData, _, err := decryptBundle(encryptedData, &bundleConfig, utils.PassphraseSource{Pass: comparePasses[path], File: comparePassFiles[path]}, passModes[path])
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			hashes[i] = make(map[string]string)
+			for key, value := range dotenv.Parse(decryptedData).Map() {
+				hashes[i][key] = hasher.Hash(value)
+			}
+		}
+
+		report := buildCompareReport(args, hashes)
+
+		if compareFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return fmt.Errorf("failed to encode report: %w", err)
+			}
+		} else {
+			printCompareReport(report)
+		}
+
+		if !report.InSync {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("key sets differ across %d bundles", len(args))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	compareCmd.Flags().StringToStringVarP(&compareProjects, "project", "", nil, "Use another project's cached key for a bundle (bundle=project)")
+	compareCmd.Flags().StringToStringVarP(&comparePasses, "pass", "p", nil, "Passphrase for a bundle (bundle=passphrase)")
+	compareCmd.Flags().StringToStringVarP(&comparePassFiles, "pass-file", "", nil, "Read a bundle's passphrase from file (bundle=file)")
+	compareCmd.Flags().StringSliceVarP(&comparePassModes, "pass-mode", "", nil, "Prompt for the passphrase of these bundles")
+	compareCmd.Flags().StringVarP(&compareFormat, "format", "", "text", "Output format (text|json)")
+}
+
+// buildCompareReport turns per-bundle value hashes into a key matrix
+func buildCompareReport(bundles []string, hashes []map[string]string) compareReport {
+	keySet := make(map[string]bool)
+	for _, bundleHashes := range hashes {
+		for key := range bundleHashes {
+			keySet[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	report := compareReport{Bundles: bundles, Keys: []compareRow{}, InSync: true}
+	for _, key := range keys {
+		row := compareRow{Key: key, Present: make([]bool, len(bundles))}
+		distinct := make(map[string]bool)
+		count := 0
+
+		for i := range bundles {
+			hash, ok := hashes[i][key]
+			row.Present[i] = ok
+			if ok {
+				distinct[hash] = true
+				count++
+			}
+		}
+
+		switch {
+		case count < 2:
+			row.Values = "single"
+		case len(distinct) == 1:
+			row.Values = "same"
+		default:
+			row.Values = "different"
+		}
+
+		if count != len(bundles) {
+			report.InSync = false
+		}
+		report.Keys = append(report.Keys, row)
+	}
+
+	return report
+}
+
+// printCompareReport prints the key matrix as a table
+func printCompareReport(report compareReport) {
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "KEY\t%s\tVALUES\n", strings.Join(report.Bundles, "\t"))
+
+	for _, row := range report.Keys {
+		cells := make([]string, len(report.Bundles))
+		for i := range report.Bundles {
+			if row.Present[i] {
+				cells[i] = "✓"
+			} else {
+				cells[i] = "✗"
+			}
+		}
+		values := row.Values
+		if values == "single" {
+			values = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", row.Key, strings.Join(cells, "\t"), values)
+	}
+	w.Flush()
+
+	fmt.Fprintln(stdout)
+	if report.InSync {
+		fmt.Fprintf(stdout, "✅ All %d bundles have the same keys\n", len(report.Bundles))
+	} else {
+		fmt.Fprintf(stdout, "⚠️  Some keys are missing from at least one bundle\n")
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestBuildCompareReport(t *testing.T) {
+	bundles := []string{"dev.envsnap", "prod.envsnap"}
+	hashes := []map[string]string{
+		{"SHARED": "h1", "CHANGED": "h2", "DEV_ONLY": "h3"},
+		{"SHARED": "h1", "CHANGED": "h4"},
+	}
+
+	report := buildCompareReport(bundles, hashes)
+
+	if report.InSync {
+		t.Error("InSync = true, want false when a key is missing")
+	}
+
+	expected := map[string]string{
+		"CHANGED":  "different",
+		"DEV_ONLY": "single",
+		"SHARED":   "same",
+	}
+	if len(report.Keys) != len(expected) {
+		t.Fatalf("got %d keys, want %d", len(report.Keys), len(expected))
+	}
+	for _, row := range report.Keys {
+		if row.Values != expected[row.Key] {
+			t.Errorf("%s values = %s, want %s", row.Key, row.Values, expected[row.Key])
+		}
+	}
+
+	if !buildCompareReport(bundles[:1], hashes[:1]).InSync {
+		t.Error("InSync = false for a single bundle, want true")
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffPass       passFlags
+	diffShowValues bool
+	diffYes        bool
+	diffFormat     string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Show which keys changed between two bundles or a bundle and a .env",
+	Long: `Show added, removed and changed keys between two files. Each side may be an
+encrypted bundle, which is decrypted in memory, or a plaintext .env file.
+
+Values are masked unless --show-values is given, which asks for confirmation
+first (--yes skips it). Like git diff --exit-code, the command exits with
+code 11 when anything changed, and 1 when the files could not be compared.`,
+	Example: `  secretsnap diff old.envsnap new.envsnap
+  secretsnap diff secrets.envsnap .env`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diffFormat != "text" && diffFormat != "json" {
+			return fmt.Errorf("format must be 'text' or 'json', got '%s'", diffFormat)
+		}
+
+		if diffShowValues && !diffYes && !utils.Confirm("⚠️  Show secret values in plain text?") {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("aborted")
+		}
+
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		// Resolve the bundle key once so two bundles only prompt once
+		var key *bundleKey
+		load := func(path string) (*dotenv.File, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			if !crypto.IsBundle(data) {
+				return dotenv.Parse(data), nil
+			}
+
+			if key == nil {
+				key, err = resolveBundleKey(projectConfig, diffPass.PassphraseSource, diffPass.mode)
+				if err != nil {
+					return nil, err
+				}
+			}
+			decryptedData, err := key.decryptResolved(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			return dotenv.Parse(decryptedData), nil
+		}
+
+		before, err := load(args[0])
+		if err != nil {
+			return err
+		}
+		after, err := load(args[1])
+		if err != nil {
+			return err
+		}
+
+		changes := dotenv.Diff(before, after)
+		if !diffShowValues {
+			for i := range changes {
+				changes[i].Old, changes[i].New = "", ""
+			}
+		}
+
+		if diffFormat == "json" {
+			if changes == nil {
+				changes = []dotenv.Change{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(changes); err != nil {
+				return fmt.Errorf("failed to encode changes: %w", err)
+			}
+		} else {
+			printDiff(changes)
+		}
+
+		if len(changes) > 0 {
+			cmd.SilenceUsage = true
+			return withExitCode(exitDiffers, fmt.Errorf("%d key(s) differ between %s and %s", len(changes), args[0], args[1]))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addPassFlags(diffCmd, &diffPass)
+	diffCmd.Flags().BoolVarP(&diffShowValues, "show-values", "", false, "Show values instead of masking them")
+	diffCmd.Flags().BoolVarP(&diffYes, "yes", "y", false, "Do not ask for confirmation with --show-values")
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "", "text", "Output format (text|json)")
+}
+
+// printDiff prints one line per change. Values are only printed when they
+// were kept by the caller.
+func printDiff(changes []dotenv.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(stdout, "✅ No changes")
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Kind]++
+		switch change.Kind {
+		case dotenv.Added:
+			fmt.Fprintf(stdout, "+ %s%s\n", change.Key, maskedValue(change.New))
+		case dotenv.Removed:
+			fmt.Fprintf(stdout, "- %s%s\n", change.Key, maskedValue(change.Old))
+		case dotenv.Changed:
+			if diffShowValues {
+				fmt.Fprintf(stdout, "~ %s=%s -> %s\n", change.Key, change.Old, change.New)
+			} else {
+				fmt.Fprintf(stdout, "~ %s (value changed)\n", change.Key)
+			}
+		}
+	}
+
+	fmt.Fprintf(stdout, "\n📊 %d added, %d removed, %d changed\n", counts[dotenv.Added], counts[dotenv.Removed], counts[dotenv.Changed])
+}
+
+func maskedValue(value string) string {
+	if !diffShowValues {
+		return "=********"
+	}
+	return "=" + value
+}
@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"secretsnap/internal/api"
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// Doctor check results
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+var (
+	doctorOffline bool
+	doctorFormat  string
+)
+
+// doctorCheck is the outcome of one diagnostic
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration, keys, permissions and connectivity",
+	Long: `Run a series of read-only checks and report pass, warn or fail for each,
+with a hint on how to fix it:
+
+  • .secretsnap.json is valid and its mode is known
+  • the key cache has a key for the project
+  • the global secretsnap directories and their files are private
+  • .gitignore has the secretsnap entries and .env is ignored
+  • the bundle decrypts with the cached key
+  • a login token is present and not expired
+  • the API is reachable (skipped with --offline)
+
+The command exits non-zero when any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if doctorFormat != "text" && doctorFormat != "json" {
+			return fmt.Errorf("format must be 'text' or 'json', got '%s'", doctorFormat)
+		}
+
+		checks := runDoctorChecks()
+
+		if doctorFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(checks); err != nil {
+				return fmt.Errorf("failed to encode checks: %w", err)
+			}
+		} else {
+			printDoctorChecks(checks)
+		}
+
+		failed := 0
+		for _, check := range checks {
+			if check.Status == checkFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolVarP(&doctorOffline, "offline", "", false, "Skip the API reachability check")
+	doctorCmd.Flags().StringVarP(&doctorFormat, "format", "", "text", "Output format (text|json)")
+}
+
+// runDoctorChecks runs every check. Nothing is created or modified.
+func runDoctorChecks() []doctorCheck {
+	var checks []doctorCheck
+
+	projectConfig, check := checkProjectConfig()
+	checks = append(checks, check)
+
+	keyBytes, check := checkKeyCache(projectConfig)
+	checks = append(checks, check)
+
+	checks = append(checks, checkPermissions())
+	checks = append(checks, checkGitignore())
+	checks = append(checks, checkEnvIgnored(projectConfig))
+	checks = append(checks, checkBundleDecrypts(projectConfig, keyBytes))
+	checks = append(checks, checkToken(projectConfig))
+
+	if !doctorOffline {
+		checks = append(checks, checkAPI(projectConfig))
+	}
+
+	return checks
+}
+
+func checkProjectConfig() (*config.ProjectConfig, doctorCheck) {
+	check := doctorCheck{Name: "Project config"}
+	path := config.GetProjectConfigPath()
+
+	projectConfig, err := config.FindProjectConfig()
+	switch {
+	case errors.Is(err, config.ErrNoProjectConfig):
+		check.Status, check.Message, check.Fix = checkWarn, path+" not found", "Run `secretsnap init`"
+		return nil, check
+	case err != nil:
+		check.Status, check.Message, check.Fix = checkFail, err.Error(), "Fix the JSON in "+path+" or delete it and run `secretsnap init`"
+		return nil, check
+	}
+
+	projectConfig, err = projectConfig.ForEnv(selectedEnv())
+	if err != nil {
+		check.Status, check.Message, check.Fix = checkFail, err.Error(), "Declare it under \"environments\" in "+path
+		return nil, check
+	}
+
+	switch projectConfig.Mode {
+	case "local", "passphrase", "cloud":
+	default:
+		check.Status = checkFail
+		check.Message = fmt.Sprintf("unknown mode '%s'", projectConfig.Mode)
+		check.Fix = "Set \"mode\" to local, passphrase or cloud in " + path
+		return projectConfig, check
+	}
+
+	if projectConfig.ProjectName == "" {
+		check.Status, check.Message, check.Fix = checkFail, "project_name is empty", "Set \"project_name\" in "+path
+		return projectConfig, check
+	}
+
+	check.Status = checkPass
+	check.Message = fmt.Sprintf("project '%s', mode %s", projectConfig.ProjectName, projectConfig.Mode)
+	if projectConfig.Env != "" {
+		check.Message += ", environment " + projectConfig.Env
+	}
+	return projectConfig, check
+}
+
+func checkKeyCache(projectConfig *config.ProjectConfig) ([]byte, doctorCheck) {
+	check := doctorCheck{Name: "Key cache"}
+	if projectConfig == nil {
+		check.Status, check.Message = checkWarn, "skipped, no project config"
+		return nil, check
+	}
+
+	// Read without loading: loading would create the file
+	keys, err := config.ReadKeysConfig()
+	switch {
+	case errors.Is(err, config.ErrKeystoreLocked):
+		check.Status, check.Message = checkWarn, "the key store is encrypted and locked"
+		check.Fix = "Run `secretsnap keystore unlock`"
+		return nil, check
+	case err != nil:
+		check.Status, check.Message = checkFail, err.Error()
+		check.Fix = "Restore it from a backup, or re-import the keys from a teammate"
+		return nil, check
+	}
+
+	key, ok := keys.Projects[projectConfig.ProjectName]
+	if !ok {
+		check.Status = checkFail
+		if projectConfig.Mode != "local" {
+			check.Status = checkWarn
+		}
+		check.Message = fmt.Sprintf("no cached key for '%s'", projectConfig.ProjectName)
+		check.Fix = fmt.Sprintf("Ask a teammate to run `secretsnap key export --project %s`, or use `--pass`", projectConfig.ProjectName)
+		return nil, check
+	}
+
+	keyBytes, err := crypto.KeyFromBase64(key.KeyB64)
+	if err != nil {
+		check.Status, check.Message = checkFail, fmt.Sprintf("cached key for '%s' is corrupt: %v", projectConfig.ProjectName, err)
+		check.Fix = "Re-import the key from a teammate"
+		return nil, check
+	}
+
+	check.Status = checkPass
+	check.Message = fmt.Sprintf("key %s cached for '%s'", key.KeyID, projectConfig.ProjectName)
+	return keyBytes, check
+}
+
+func checkPermissions() doctorCheck {
+	check := doctorCheck{Name: "Permissions"}
+	if runtime.GOOS == "windows" {
+		check.Status, check.Message = checkPass, "skipped on Windows"
+		return check
+	}
+
+	var loose []string
+	var fixes []string
+	dirs := []string{config.GetConfigDir()}
+	if config.GetStateDir() != dirs[0] {
+		dirs = append(dirs, config.GetStateDir())
+	}
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			check.Status, check.Message = checkFail, err.Error()
+			return check
+		}
+		if info.Mode().Perm()&0077 != 0 {
+			loose = append(loose, fmt.Sprintf("%s is %v", dir, info.Mode().Perm()))
+			fixes = append(fixes, "chmod 700 "+dir)
+		}
+	}
+	for _, path := range []string{config.GetKeysConfigPath(), config.GetTokenPath(), config.GetUsagePath()} {
+		if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0077 != 0 {
+			loose = append(loose, fmt.Sprintf("%s is %v", filepath.Base(path), info.Mode().Perm()))
+			fixes = append(fixes, "chmod 600 "+path)
+		}
+	}
+
+	if len(loose) > 0 {
+		check.Status = checkFail
+		check.Message = "readable by others: " + strings.Join(loose, ", ")
+		check.Fix = strings.Join(fixes, " && ")
+		return check
+	}
+
+	check.Status, check.Message = checkPass, dirs[0]+" is private"
+	if len(dirs) > 1 {
+		check.Message = strings.Join(dirs, " and ") + " are private"
+	}
+	return check
+}
+
+func checkGitignore() doctorCheck {
+	check := doctorCheck{Name: "Gitignore"}
+	path := config.GetGitignorePath()
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		check.Status, check.Message = checkFail, err.Error()
+		return check
+	}
+
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		lines[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, entry := range config.GitignoreEntries {
+		if !lines[entry] {
+			missing = append(missing, entry)
+		}
+	}
+
+	if len(missing) > 0 {
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("%s is missing %s", path, strings.Join(missing, ", "))
+		check.Fix = "Run `secretsnap init` to add them"
+		return check
+	}
+
+	check.Status, check.Message = checkPass, "secretsnap entries present"
+	return check
+}
+
+// checkEnvIgnored checks the project's .env, which is not the one in the
+// current directory when doctor runs from a subdirectory
+func checkEnvIgnored(projectConfig *config.ProjectConfig) doctorCheck {
+	check := doctorCheck{Name: ".env ignored"}
+
+	dir := "."
+	if projectConfig != nil && projectConfig.Dir != "" {
+		dir = projectConfig.Dir
+	}
+
+	if _, err := gitOutput("-C", dir, "rev-parse", "--git-dir"); err != nil {
+		check.Status, check.Message = checkPass, "skipped, not a git repository"
+		return check
+	}
+
+	if err := exec.Command("git", "-C", dir, "check-ignore", "-q", ".env").Run(); err == nil {
+		check.Status, check.Message = checkPass, ".env is ignored by git"
+		return check
+	}
+
+	if _, err := gitOutput("-C", dir, "ls-files", "--error-unmatch", ".env"); err == nil {
+		check.Status, check.Message = checkFail, ".env is tracked by git"
+		check.Fix = "git rm --cached .env, add .env to .gitignore, and rotate the values it contained"
+		return check
+	}
+
+	check.Status, check.Message = checkWarn, ".env is not ignored by git"
+	check.Fix = "Add .env to .gitignore"
+	return check
+}
+
+func checkBundleDecrypts(projectConfig *config.ProjectConfig, keyBytes []byte) doctorCheck {
+	check := doctorCheck{Name: "Bundle"}
+	if projectConfig == nil {
+		check.Status, check.Message = checkWarn, "skipped, no project config"
+		return check
+	}
+
+	path := defaultBundlePath(projectConfig)
+	encryptedData, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		check.Status, check.Message, check.Fix = checkWarn, path+" not found", "Create it with `secretsnap bundle .env`"
+		return check
+	}
+	if err != nil {
+		check.Status, check.Message = checkFail, err.Error()
+		return check
+	}
+
+	if !crypto.IsBundle(encryptedData) {
+		check.Status, check.Message = checkFail, path+" is not an encrypted bundle"
+		check.Fix = "Re-create it with `secretsnap bundle .env`"
+		return check
+	}
+
+	if keyBytes == nil {
+		check.Status, check.Message = checkWarn, "skipped, no cached key"
+		return check
+	}
+
+	if _, err := crypto.DecryptWithKey(encryptedData, keyBytes); err != nil {
+		check.Status = checkFail
+		if projectConfig.Mode != "local" {
+			check.Status = checkWarn
+		}
+		check.Message = path + " does not decrypt with the cached key"
+		check.Fix = "It may use a passphrase (`--pass`) or another project's key"
+		return check
+	}
+
+	check.Status, check.Message = checkPass, path+" decrypts with the cached key"
+	return check
+}
+
+func checkToken(projectConfig *config.ProjectConfig) doctorCheck {
+	check := doctorCheck{Name: "Login token"}
+	cloud := projectConfig != nil && projectConfig.Mode == "cloud"
+
+	token, err := config.LoadToken()
+	if err != nil {
+		check.Status, check.Message = checkFail, err.Error()
+		return check
+	}
+
+	if token == "" {
+		if cloud {
+			check.Status, check.Message, check.Fix = checkFail, "not logged in", "Run `secretsnap login --license <KEY>`"
+		} else {
+			check.Status, check.Message = checkPass, "not logged in (only needed for cloud mode)"
+		}
+		return check
+	}
+
+	expiry, ok := tokenExpiry(token)
+	switch {
+	case !ok:
+		check.Status, check.Message = checkWarn, "token present, expiry unknown"
+	case time.Now().After(expiry):
+		check.Status = checkWarn
+		if cloud {
+			check.Status = checkFail
+		}
+		check.Message = "token expired on " + expiry.Format("2006-01-02")
+		check.Fix = "Run `secretsnap login --license <KEY>`"
+	default:
+		check.Status, check.Message = checkPass, "token valid until "+expiry.Format("2006-01-02")
+	}
+	return check
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}
+
+func checkAPI(projectConfig *config.ProjectConfig) doctorCheck {
+	check := doctorCheck{Name: "API"}
+	url := utils.GetAPIURL()
+
+	if err := api.NewClient(url, "").Ping(5 * time.Second); err != nil {
+		check.Status = checkWarn
+		if projectConfig != nil && projectConfig.Mode == "cloud" {
+			check.Status = checkFail
+		}
+		check.Message = fmt.Sprintf("%s is unreachable: %v", url, err)
+		check.Fix = "Check your network or proxy, or DEV_SECRETSNAP_API_URL"
+		return check
+	}
+
+	check.Status, check.Message = checkPass, url+" is reachable"
+	return check
+}
+
+// printDoctorChecks prints one line per check followed by a summary
+func printDoctorChecks(checks []doctorCheck) {
+	icons := map[string]string{checkPass: "✅", checkWarn: "⚠️ ", checkFail: "❌"}
+	counts := make(map[string]int)
+
+	width := 0
+	for _, check := range checks {
+		if len(check.Name) > width {
+			width = len(check.Name)
+		}
+	}
+
+	for _, check := range checks {
+		counts[check.Status]++
+		fmt.Fprintf(stdout, "%s %-*s  %s\n", icons[check.Status], width, check.Name, check.Message)
+		if check.Fix != "" && check.Status != checkPass {
+			fmt.Fprintf(stdout, "   %-*s  💡 %s\n", width, "", check.Fix)
+		}
+	}
+
+	fmt.Fprintf(stdout, "\n🩺 %d passed, %d warnings, %d failed\n", counts[checkPass], counts[checkWarn], counts[checkFail])
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestTokenExpiry(t *testing.T) {
+	jwt := func(payload string) string {
+		return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		expected time.Time
+		ok       bool
+	}{
+		{name: "with exp", token: jwt(`{"sub":"u1","exp":1700000000}`), expected: time.Unix(1700000000, 0), ok: true},
+		{name: "trailing newline", token: jwt(`{"exp":1700000000}`) + "\n", expected: time.Unix(1700000000, 0), ok: true},
+		{name: "without exp", token: jwt(`{"sub":"u1"}`), ok: false},
+		{name: "not a jwt", token: "opaque-token", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiry, ok := tokenExpiry(tt.token)
+			if ok != tt.ok || !expiry.Equal(tt.expected) {
+				t.Errorf("tokenExpiry() = %v, %v, want %v, %v", expiry, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
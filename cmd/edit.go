@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	editPass passFlags
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit [bundle]",
+	Short: "Edit a bundle in your editor without leaving plaintext behind",
+	Long: `Decrypt a bundle to a private temporary file, open it in $VISUAL or $EDITOR
+(vi by default), and re-encrypt it with the same key when the editor exits.
+The temporary file is removed afterwards.
+
+Bundles with merge conflicts from the git merge driver contain conflict
+markers; the bundle is not saved until all of them are resolved.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		bundlePath := defaultBundlePath(projectConfig)
+		if len(args) == 1 {
+			bundlePath = args[0]
+		}
+
+		encryptedData, err := readBundleFile(bundlePath)
+		if err != nil {
+			return err
+		}
+
+		bundleKey, err := resolveBundleKey(projectConfig, editPass.PassphraseSource, editPass.mode)
+		if err != nil {
+			return err
+		}
+
+		original, err := bundleKey.decrypt(encryptedData)
+		if err != nil {
+			return err
+		}
+
+		tempDir, err := os.MkdirTemp("", "secretsnap-edit-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		tempFile := filepath.Join(tempDir, ".env")
+		defer func() {
+			// Overwrite before removing so the plaintext does not linger
+			if info, err := os.Stat(tempFile); err == nil {
+				os.WriteFile(tempFile, make([]byte, info.Size()), 0600)
+			}
+			os.RemoveAll(tempDir)
+		}()
+
+		if err := os.WriteFile(tempFile, original, 0600); err != nil {
+			return fmt.Errorf("failed to write temp file: %w", err)
+		}
+
+		var edited []byte
+		for {
+			if err := runEditor(tempFile); err != nil {
+				return err
+			}
+
+			edited, err = os.ReadFile(tempFile)
+			if err != nil {
+				return fmt.Errorf("failed to read temp file: %w", err)
+			}
+
+			if !dotenv.Parse(edited).HasConflicts() {
+				break
+			}
+			if !utils.Confirm("⚠️  Conflict markers remain. Edit again?") {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("conflict markers remain, %s was not changed", bundlePath)
+			}
+		}
+
+		if bytes.Equal(edited, original) {
+			humanf("✅ No changes to %s\n", bundlePath)
+			return nil
+		}
+
+		encryptedData, err = bundleKey.encrypt(edited)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFileAtomic(bundlePath, encryptedData, 0644); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+
+		humanf("✅ Saved %s\n", bundlePath)
+		return nil
+	},
+}
+
+func init() {
+	addPassFlags(editCmd, &editPass)
+}
+
+// runEditor opens path in the user's editor and waits for it to exit
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Editors are often configured with arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	command := exec.Command(fields[0], append(fields[1:], path)...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	return nil
+}
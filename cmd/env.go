@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"secretsnap/internal/config"
+)
+
+// envName is the global --env flag
+var envName string
+
+// projectDirFlag is the global --project-dir flag
+var projectDirFlag string
+
+// configDirFlag is the global --config-dir flag
+var configDirFlag string
+
+// selectedEnv returns the environment chosen with --env or SECRETSNAP_ENV
+func selectedEnv() string {
+	if envName != "" {
+		return envName
+	}
+	return os.Getenv("SECRETSNAP_ENV")
+}
+
+// readProjectConfig loads the discovered project configuration without
+// creating one; outside a project the defaults are used in memory
+func readProjectConfig() (*config.ProjectConfig, error) {
+	projectConfig, err := config.FindProjectConfig()
+	if errors.Is(err, config.ErrNoProjectConfig) {
+		return config.DefaultProjectConfig(config.ProjectDir()), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project config: %w", err)
+	}
+	return projectConfig, nil
+}
+
+// loadProjectConfig loads the project configuration with the selected
+// environment applied
+func loadProjectConfig() (*config.ProjectConfig, error) {
+	projectConfig, err := readProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return projectConfig.ForEnv(selectedEnv())
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"secretsnap/internal/api"
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes. They are documented in the README and must not change.
+const (
+	exitOK           = 0
+	exitError        = 1  // any other failure, including failed checks
+	exitUsage        = 2  // invalid flags or arguments
+	exitNotFound     = 3  // bundle, project or file does not exist
+	exitWrongKey     = 4  // key or passphrase does not open the bundle
+	exitNoProjectKey = 5  // no cached key or project config
+	exitUnauthorized = 6  // not logged in or token rejected
+	exitPlanRequired = 7  // feature needs a paid plan
+	exitNetwork      = 8  // API unreachable
+	exitServer       = 9  // API returned a 5xx
+	exitLocked       = 10 // key store is encrypted and not unlocked
+	exitDiffers      = 11 // diff found changed keys
+)
+
+// codedError gives an error a specific exit code
+type codedError struct {
+	err  error
+	code int
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// withExitCode makes err exit with code
+func withExitCode(code int, err error) error {
+	return &codedError{err: err, code: code}
+}
+
+// childExitError passes a child process's exit code through `run`. The
+// child has already reported its own failure, so nothing is printed.
+type childExitError struct {
+	code int
+}
+
+func (e *childExitError) Error() string { return fmt.Sprintf("command exited with status %d", e.code) }
+
+// exitCode maps an error to the process exit code
+func exitCode(err error) int {
+	var child *childExitError
+	var coded *codedError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &child):
+		return child.code
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, crypto.ErrWrongKey):
+		return exitWrongKey
+	case errors.Is(err, config.ErrNoProjectKey), errors.Is(err, config.ErrNoProjectConfig):
+		return exitNoProjectKey
+	case errors.Is(err, config.ErrKeystoreLocked):
+		return exitLocked
+	case errors.Is(err, config.ErrNotLoggedIn), errors.Is(err, api.ErrUnauthorized):
+		return exitUnauthorized
+	case errors.Is(err, api.ErrPlanRequired):
+		return exitPlanRequired
+	case errors.Is(err, api.ErrNotFound), errors.Is(err, os.ErrNotExist):
+		return exitNotFound
+	case errors.Is(err, api.ErrNetwork):
+		return exitNetwork
+	case errors.Is(err, api.ErrServer):
+		return exitServer
+	}
+	return exitError
+}
+
+// Execute runs the root command, reports any error once on stderr and
+// returns the exit code for it
+func Execute(rootCmd *cobra.Command) int {
+	err := rootCmd.Execute()
+	if err == nil {
+		return exitOK
+	}
+
+	var child *childExitError
+	if !errors.As(err, &child) {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+	}
+	return exitCode(err)
+}
+
+// usageErrors makes flag and argument errors of cmd and its subcommands
+// exit with exitUsage
+func usageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(exitUsage, err)
+	})
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(cmd *cobra.Command, a []string) error {
+			if err := args(cmd, a); err != nil {
+				return withExitCode(exitUsage, err)
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		usageErrors(sub)
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"secretsnap/internal/api"
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, exitOK},
+		{"generic", errors.New("boom"), exitError},
+		{"usage", withExitCode(exitUsage, errors.New("bad flag")), exitUsage},
+		{"wrong key", fmt.Errorf("failed to decrypt: %w", crypto.ErrWrongKey), exitWrongKey},
+		{"no project key", fmt.Errorf("%w for project 'x'", config.ErrNoProjectKey), exitNoProjectKey},
+		{"not logged in", fmt.Errorf("%w. Run login", config.ErrNotLoggedIn), exitUnauthorized},
+		{"unauthorized", fmt.Errorf("pull: %w", api.ErrUnauthorized), exitUnauthorized},
+		{"plan required", fmt.Errorf("push: %w", api.ErrPlanRequired), exitPlanRequired},
+		{"not found", fmt.Errorf("pull: %w", api.ErrNotFound), exitNotFound},
+		{"network", fmt.Errorf("push: %w", api.ErrNetwork), exitNetwork},
+		{"server", fmt.Errorf("push: %w", api.ErrServer), exitServer},
+		{"differs", withExitCode(exitDiffers, errors.New("2 key(s) differ")), exitDiffers},
+		{"child", &childExitError{code: 42}, 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"secretsnap/internal/dotenv"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exampleOutFile string
+	examplePass    passFlags
+	exampleCheck   bool
+	exampleForce   bool
+)
+
+var exampleCmd = &cobra.Command{
+	Use:   "example [path-to-bundle]",
+	Short: "Generate or check .env.example from a bundle",
+	Long: `Write a .env.example listing every key in the bundle. Values are replaced by
+placeholders that describe their shape (e.g. <number>, <https-url>) and comments
+are kept.
+
+With --check, nothing is written: the command fails when the keys in the
+committed example differ from the keys in the bundle, which makes it suitable
+for CI. The bundle defaults to the project's bundle_path.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		inputFile := defaultBundlePath(projectConfig)
+		if len(args) == 1 {
+			inputFile = args[0]
+		}
+
+		encryptedData, err := readBundleFile(inputFile)
+		if err != nil {
+			return err
+		}
+
+		decryptedData, _, err := decryptBundle(encryptedData, projectConfig, examplePass.PassphraseSource, examplePass.mode)
+		if err != nil {
+			return err
+		}
+
+		bundleEnv := dotenv.Parse(decryptedData)
+
+		if exampleCheck {
+			existing, err := os.ReadFile(exampleOutFile)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", exampleOutFile, err)
+			}
+
+			missing, extra := dotenv.KeyDiff(bundleEnv.Keys(), dotenv.Parse(existing).Keys())
+			if len(missing) == 0 && len(extra) == 0 {
+				fmt.Fprintf(stdout, "✅ %s matches %s (%d keys)\n", exampleOutFile, inputFile, len(bundleEnv.Keys()))
+				return nil
+			}
+
+			if len(missing) > 0 {
+				fmt.Fprintf(stdout, "➕ In bundle but missing from %s: %s\n", exampleOutFile, strings.Join(missing, ", "))
+			}
+			if len(extra) > 0 {
+				fmt.Fprintf(stdout, "➖ In %s but not in bundle: %s\n", exampleOutFile, strings.Join(extra, ", "))
+			}
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s is out of date. Run `secretsnap example --force` to regenerate it", exampleOutFile)
+		}
+
+		// Check if output file exists and handle --force
+		if _, err := os.Stat(exampleOutFile); err == nil && !exampleForce {
+			return fmt.Errorf("refusing to overwrite %s. Use `--force`", exampleOutFile)
+		}
+
+		if err := os.WriteFile(exampleOutFile, dotenv.Example(bundleEnv), 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+
+		humanf("✅ Wrote %d keys from %s to %s\n", len(bundleEnv.Keys()), inputFile, exampleOutFile)
+		return nil
+	},
+}
+
+func init() {
+	exampleCmd.Flags().StringVarP(&exampleOutFile, "out", "o", ".env.example", "Example file path")
+	addPassFlags(exampleCmd, &examplePass)
+	exampleCmd.Flags().BoolVarP(&exampleCheck, "check", "", false, "Fail if the example's keys differ from the bundle's")
+	exampleCmd.Flags().BoolVarP(&exampleForce, "force", "f", false, "Overwrite output file if it exists")
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"secretsnap/internal/refs"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainPass   passFlags
+	explainFormat string
+)
+
+// explainLayer is one row of the explain report
+type explainLayer struct {
+	Layer     string `json:"layer"`
+	Path      string `json:"path,omitempty"`
+	Defines   bool   `json:"defines"`
+	Final     bool   `json:"final"`
+	Reference string `json:"reference,omitempty"`
+	Skipped   string `json:"skipped,omitempty"`
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain KEY",
+	Short: "Show which layer a key's final value comes from",
+	Long: `List the layers that 'run' and 'unbundle' compose (see "layers" in
+.secretsnap.json), whether each one defines KEY, and which one wins. Values are
+never printed; references are shown as written.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if explainFormat != "text" && explainFormat != "json" {
+			return fmt.Errorf("format must be 'text' or 'json', got '%s'", explainFormat)
+		}
+		key := args[0]
+
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		_, layers, _, err := composeEnv(projectConfig, "", explainPass.PassphraseSource, explainPass.mode, false)
+		if err != nil {
+			return err
+		}
+
+		var rows []explainLayer
+		final := -1
+		for _, l := range layers {
+			row := explainLayer{Layer: l.Name, Path: l.Path, Skipped: l.Skipped}
+			if l.Skipped == "" && l.defines(key) {
+				row.Defines = true
+				if l.File != nil {
+					value, _ := l.File.Get(key)
+					if _, ok := refs.Parse(value); ok {
+						row.Reference = value
+					}
+				}
+				// The process layer only overrides keys set by an earlier layer
+				if l.Kind != "process" || final >= 0 {
+					final = len(rows)
+				}
+			}
+			rows = append(rows, row)
+		}
+		if final >= 0 {
+			rows[final].Final = true
+		}
+
+		if explainFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(rows); err != nil {
+				return fmt.Errorf("failed to encode report: %w", err)
+			}
+		} else {
+			fmt.Fprintf(stdout, "🔍 %s\n", key)
+			for _, row := range rows {
+				line := row.Layer
+				if row.Path != "" && !strings.Contains(row.Layer, row.Path) {
+					line += " (" + row.Path + ")"
+				}
+				switch {
+				case row.Skipped != "":
+					fmt.Fprintf(stdout, "   - %s: skipped, %s\n", line, row.Skipped)
+				case row.Final:
+					fmt.Fprintf(stdout, "   ✅ %s: defines %s (final value)\n", line, key)
+				case row.Defines:
+					fmt.Fprintf(stdout, "   ✓ %s: defines %s (overridden)\n", line, key)
+				default:
+					fmt.Fprintf(stdout, "   ✗ %s: not set\n", line)
+				}
+				if row.Reference != "" {
+					fmt.Fprintf(stdout, "      → %s\n", row.Reference)
+				}
+			}
+		}
+
+		if final < 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s is not defined in any layer", key)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addPassFlags(explainCmd, &explainPass)
+	explainCmd.Flags().StringVarP(&explainFormat, "format", "", "text", "Output format (text|json)")
+}
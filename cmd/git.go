@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+
+	"github.com/spf13/cobra"
+)
+
+// gitAttributesLine routes bundles through the secretsnap drivers
+const gitAttributesLine = "*.envsnap diff=secretsnap merge=secretsnap"
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Git integration for bundles",
+}
+
+var gitInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Configure git to diff and merge bundles key by key",
+	Long: `Add *.envsnap to .gitattributes and register two drivers in the repository's
+git config:
+
+  diff   'git diff' shows keys with hashed values instead of ciphertext
+  merge  bundles are decrypted and merged key by key, then re-encrypted
+
+Conflicting keys are written as conflict markers inside the encrypted bundle;
+resolve them with 'secretsnap edit'. The drivers use the cached project key,
+so they work for local-mode bundles only.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		top, err := gitOutput("rev-parse", "--show-toplevel")
+		if err != nil {
+			return fmt.Errorf("not inside a git repository: %w", err)
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate secretsnap executable: %w", err)
+		}
+		if strings.ContainsAny(exe, " \t") {
+			exe = "'" + exe + "'"
+		}
+
+		settings := [][2]string{
+			{"diff.secretsnap.textconv", exe + " git textconv"},
+			{"diff.secretsnap.cachetextconv", "false"},
+			{"merge.secretsnap.name", "secretsnap key-wise merge"},
+			{"merge.secretsnap.driver", exe + " git merge %O %A %B %P"},
+		}
+		for _, setting := range settings {
+			if _, err := gitOutput("config", "--local", setting[0], setting[1]); err != nil {
+				return fmt.Errorf("failed to set %s: %w", setting[0], err)
+			}
+		}
+
+		attributesFile := filepath.Join(top, ".gitattributes")
+		added, err := ensureLine(attributesFile, gitAttributesLine)
+		if err != nil {
+			return err
+		}
+
+		humanf("✅ Configured git diff and merge drivers for *.envsnap\n")
+		if added {
+			humanf("📝 Added '%s' to %s (commit it so teammates get it too)\n", gitAttributesLine, attributesFile)
+		}
+		humanf("💡 Each teammate runs `secretsnap git install` once, since git config is not committed\n")
+
+		return nil
+	},
+}
+
+var gitTextconvCmd = &cobra.Command{
+	Use:    "textconv <file>",
+	Short:  "Print a bundle as keys with hashed values (git diff driver)",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+		if len(data) == 0 {
+			return nil
+		}
+
+		// Never fail a git diff: describe what went wrong instead
+		keyBytes, env, err := decryptForGit(data, ".")
+		if err != nil {
+			fmt.Printf("# secretsnap: cannot decrypt (%v)\n", err)
+			fmt.Printf("# ciphertext sha256:%x\n", sha256.Sum256(data))
+			return nil
+		}
+
+		fmt.Print(string(textconv(env, keyBytes)))
+		return nil
+	},
+}
+
+var gitMergeCmd = &cobra.Command{
+	Use:    "merge <base> <ours> <theirs> [path]",
+	Short:  "Three-way merge of bundles (git merge driver)",
+	Hidden: true,
+	Args:   cobra.RangeArgs(3, 4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		path := args[1]
+		if len(args) == 4 {
+			path = args[3]
+		}
+
+		var keyBytes []byte
+		files := make([]*dotenv.File, 3)
+		for i, name := range args[:3] {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			if len(data) == 0 {
+				// A bundle added on both sides has an empty base
+				files[i] = &dotenv.File{}
+				continue
+			}
+			keyBytes, files[i], err = decryptForGit(data, filepath.Dir(path))
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+
+		if keyBytes == nil {
+			return fmt.Errorf("%s: base, ours and theirs are all empty, nothing to merge", path)
+		}
+
+		merged, conflicts := dotenv.Merge(files[0], files[1], files[2])
+
+		encryptedData, err := crypto.EncryptWithKey(merged.Bytes(), keyBytes)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt merged bundle: %w", err)
+		}
+		if err := writeFileAtomic(args[1], encryptedData, 0644); err != nil {
+			return fmt.Errorf("failed to write merged bundle: %w", err)
+		}
+
+		if len(conflicts) > 0 {
+			return fmt.Errorf("merge conflict in %s on %s. Resolve with `secretsnap edit %s`",
+				path, strings.Join(conflicts, ", "), path)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(gitInstallCmd)
+	gitCmd.AddCommand(gitTextconvCmd)
+	gitCmd.AddCommand(gitMergeCmd)
+}
+
+// decryptForGit decrypts a bundle for the git drivers with the cached key
+// of the project configured in dir, falling back to the current directory.
+// Git runs drivers without a terminal, so there is no passphrase fallback.
+func decryptForGit(data []byte, dir string) ([]byte, *dotenv.File, error) {
+	projectConfig, err := config.FindProjectConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBytes, err := loadProjectKeyBytes(configuredProject(dir, projectConfig.ProjectName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decryptedData, err := crypto.DecryptWithKey(data, keyBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	return keyBytes, dotenv.Parse(decryptedData), nil
+}
+
+// textconv renders a bundle as sorted KEY=hash lines. The hash is keyed
+// with a value derived from the project key, so it is stable across git
+// invocations but useless to anyone without the key.
+func textconv(env *dotenv.File, keyBytes []byte) []byte {
+	derived, _ := hex.DecodeString(crypto.NewValueHasherWithKey(keyBytes).Hash("secretsnap git textconv"))
+	hasher := crypto.NewValueHasherWithKey(derived)
+
+	values := env.Map()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	if env.HasConflicts() {
+		b.WriteString("# unresolved merge conflicts\n")
+	}
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=hmac:%s\n", key, hasher.Hash(values[key])[:16])
+	}
+	return b.Bytes()
+}
+
+// gitOutput runs git and returns its trimmed stdout
+func gitOutput(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	command := exec.Command("git", args...)
+	command.Stderr = &stderr
+	out, err := command.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ensureLine appends line to path unless it is already present and
+// reports whether it was added
+func ensureLine(path, line string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	for _, existing := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(existing) == line {
+			return false, nil
+		}
+	}
+
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+	content = append(content, line+"\n"...)
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/scan"
+
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies pre-commit hooks written by secretsnap
+const hookMarker = "# Installed by secretsnap hook install"
+
+var hookInstallForce bool
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Git hooks that keep plaintext secrets out of commits",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a pre-commit hook that blocks plaintext secrets",
+	Long: `Install a git pre-commit hook that blocks a commit when it stages:
+
+  • a plaintext dotenv file (.env, .env.local, prod.env, ...; .env.example is fine)
+  • any value from the project's bundle, anywhere in the staged content
+
+Values are compared through keyed hashes, so the hook never keeps plaintext
+around, and matches are reported by key, never by value. Values shorter than
+8 characters are not checked.
+
+To bypass the hook for one commit, when you are sure:
+
+  SECRETSNAP_SKIP_HOOK=1 git commit ...   (or git commit --no-verify)`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hooksDir, err := gitOutput("rev-parse", "--git-path", "hooks")
+		if err != nil {
+			return fmt.Errorf("not inside a git repository: %w", err)
+		}
+		hookPath := filepath.Join(hooksDir, "pre-commit")
+
+		if existing, err := os.ReadFile(hookPath); err == nil && !strings.Contains(string(existing), hookMarker) && !hookInstallForce {
+			return fmt.Errorf("%s already exists. Add `secretsnap hook pre-commit` to it, or use `--force` to replace it", hookPath)
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate secretsnap executable: %w", err)
+		}
+
+		script := "#!/bin/sh\n" +
+			hookMarker + "\n" +
+			"# Bypass once with: SECRETSNAP_SKIP_HOOK=1 git commit (or git commit --no-verify)\n" +
+			"exec " + shellQuote(exe) + " hook pre-commit\n"
+
+		if err := os.MkdirAll(hooksDir, 0755); err != nil {
+			return fmt.Errorf("failed to create hooks directory: %w", err)
+		}
+		if err := os.WriteFile(hookPath, []byte(script), 0755); err != nil {
+			return fmt.Errorf("failed to write hook: %w", err)
+		}
+
+		humanf("✅ Installed pre-commit hook at %s\n", hookPath)
+		humanf("💡 Bypass once with SECRETSNAP_SKIP_HOOK=1 git commit\n")
+		return nil
+	},
+}
+
+var hookPreCommitCmd = &cobra.Command{
+	Use:   "pre-commit",
+	Short: "Check staged changes for plaintext secrets (run by the hook)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if os.Getenv("SECRETSNAP_SKIP_HOOK") == "1" {
+			fmt.Fprintf(stderr, "⚠️  secretsnap: SECRETSNAP_SKIP_HOOK=1, skipping secret checks\n")
+			return nil
+		}
+
+		staged, err := gitOutput("diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+		if err != nil {
+			return fmt.Errorf("failed to list staged files: %w", err)
+		}
+		if staged == "" {
+			return nil
+		}
+
+		matcher, err := bundleMatcher()
+		if err != nil {
+			fmt.Fprintf(stderr, "⚠️  secretsnap: not checking staged content for secret values: %v\n", err)
+		}
+
+		var problems []string
+		for _, path := range strings.Split(staged, "\x00") {
+			if path == "" {
+				continue
+			}
+			if scan.IsDotenvName(path) {
+				problems = append(problems, fmt.Sprintf("%s is a plaintext dotenv file. Commit the bundle instead (`secretsnap bundle`)", path))
+				continue
+			}
+			if matcher == nil || strings.HasSuffix(path, ".envsnap") {
+				continue
+			}
+
+			// Check the staged version, not the working tree
+			content, err := exec.Command("git", "show", ":"+path).Output()
+			if err != nil || scan.IsBinary(content) {
+				continue
+			}
+			for _, match := range matcher.Find(content) {
+				problems = append(problems, match.Describe(path))
+			}
+		}
+
+		if len(problems) == 0 {
+			return nil
+		}
+
+		fmt.Fprintf(stderr, "🚫 secretsnap blocked this commit:\n")
+		for _, problem := range problems {
+			fmt.Fprintf(stderr, "   • %s\n", problem)
+		}
+		fmt.Fprintf(stderr, "\nIf you are sure, bypass once with: SECRETSNAP_SKIP_HOOK=1 git commit\n")
+		return fmt.Errorf("%d plaintext secret(s) staged", len(problems))
+	},
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVarP(&hookInstallForce, "force", "f", false, "Replace an existing pre-commit hook")
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookPreCommitCmd)
+}
+
+// shellQuote quotes s for a POSIX shell script
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// bundleMatcher decrypts the project's bundle with its cached key and
+// returns a matcher for its values. Hooks run without a terminal, so
+// passphrase bundles cannot be checked.
+func bundleMatcher() (*scan.Matcher, error) {
+	projectConfig, err := config.FindProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+	projectConfig, err = projectConfig.ForEnv(selectedEnv())
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedData, err := readBundleFile(defaultBundlePath(projectConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes, err := loadProjectKeyBytes(projectConfig.ProjectName)
+	if err != nil {
+		return nil, fmt.Errorf("no cached key for '%s'", projectConfig.ProjectName)
+	}
+
+	decryptedData, err := crypto.DecryptWithKey(encryptedData, keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt bundle: %w", err)
+	}
+
+	return scan.NewMatcher(dotenv.Parse(decryptedData).Map())
+}
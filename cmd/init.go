@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"secretsnap/internal/config"
@@ -15,64 +14,91 @@ import (
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize secretsnap configuration",
-	Long:  `Initialize secretsnap with local configuration. Creates .secretsnap.json in the current directory and generates a project key.`,
+	Long:  `Initialize secretsnap with local configuration. Creates .secretsnap.json in the current directory (or --project-dir) and generates a project key.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// A new project starts here rather than in a discovered parent
+		if projectDirFlag == "" {
+			if err := config.SetProjectDir("."); err != nil {
+				return err
+			}
+		}
+
 		// Load or create project config
 		projectConfig, err := config.LoadProjectConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+			return fmt.Errorf("failed to load project config: %w", err)
 		}
 
 		// Check if project key already exists
 		existingKey, err := config.GetProjectKey(projectConfig.ProjectName)
 		if err == nil && existingKey != nil {
-			fmt.Printf("✅ Project '%s' already initialized!\n", projectConfig.ProjectName)
-			fmt.Printf("📁 Config file: %s\n", config.GetProjectConfigPath())
-			fmt.Printf("🔧 Mode: %s\n", projectConfig.Mode)
-			fmt.Printf("🔑 Key ID: %s\n", existingKey.KeyID)
+			humanf("✅ Project '%s' already initialized!\n", projectConfig.ProjectName)
+			humanf("📁 Config file: %s\n", config.GetProjectConfigPath())
+			humanf("🔧 Mode: %s\n", projectConfig.Mode)
+			humanf("🔑 Key ID: %s\n", existingKey.KeyID)
+			if jsonOutput() {
+				return writeJSON(initResult{
+					Project:    projectConfig.ProjectName,
+					Mode:       projectConfig.Mode,
+					KeyID:      existingKey.KeyID,
+					ConfigFile: config.GetProjectConfigPath(),
+					KeysFile:   config.GetKeysConfigPath(),
+				})
+			}
 			return nil
 		}
 
 		// Generate new project key
 		keyBytes, err := crypto.GenerateProjectKey()
 		if err != nil {
-			return fmt.Errorf("failed to generate project key: %v", err)
+			return fmt.Errorf("failed to generate project key: %w", err)
 		}
 
 		keyID, err := crypto.GenerateKeyID()
 		if err != nil {
-			return fmt.Errorf("failed to generate key ID: %v", err)
+			return fmt.Errorf("failed to generate key ID: %w", err)
 		}
 
 		// Create project key
 		projectKey := &config.ProjectKey{
 			KeyID:     keyID,
-			Algorithm: "age-symmetric-v1",
+			Algorithm: keyAlgorithm,
 			KeyB64:    crypto.KeyToBase64(keyBytes),
 			CreatedAt: time.Now(),
 		}
 
 		// Save project key to cache
 		if err := config.SaveProjectKey(projectConfig.ProjectName, projectKey); err != nil {
-			return fmt.Errorf("failed to save project key: %v", err)
+			return fmt.Errorf("failed to save project key: %w", err)
 		}
 
 		// Ensure gitignore entries
 		if err := config.EnsureGitignoreEntries(); err != nil {
-			return fmt.Errorf("failed to update .gitignore: %v", err)
+			return fmt.Errorf("failed to update .gitignore: %w", err)
 		}
 
-		fmt.Printf("✅ Secretsnap initialized!\n")
-		fmt.Printf("📁 Config file: %s\n", config.GetProjectConfigPath())
-		fmt.Printf("🔧 Mode: %s\n", projectConfig.Mode)
-		fmt.Printf("📦 Project: %s\n", projectConfig.ProjectName)
-		fmt.Printf("🔑 Key ID: %s\n", projectKey.KeyID)
-		fmt.Printf("🔒 Key cached at: %s\n", config.GetKeysConfigPath())
+		humanf("✅ Secretsnap initialized!\n")
+		humanf("📁 Config file: %s\n", config.GetProjectConfigPath())
+		humanf("🔧 Mode: %s\n", projectConfig.Mode)
+		humanf("📦 Project: %s\n", projectConfig.ProjectName)
+		humanf("🔑 Key ID: %s\n", projectKey.KeyID)
+		humanf("🔒 Key cached at: %s\n", config.GetKeysConfigPath())
 
 		// Show general upsell for new users
 		if err := utils.ShowUpsell(); err != nil {
 			// Don't fail the command if upsell fails
-			fmt.Fprintf(os.Stderr, "Warning: failed to show upsell: %v\n", err)
+			fmt.Fprintf(stderr, "Warning: failed to show upsell: %v\n", err)
+		}
+
+		if jsonOutput() {
+			return writeJSON(initResult{
+				Project:    projectConfig.ProjectName,
+				Mode:       projectConfig.Mode,
+				KeyID:      projectKey.KeyID,
+				ConfigFile: config.GetProjectConfigPath(),
+				KeysFile:   config.GetKeysConfigPath(),
+				Created:    true,
+			})
 		}
 
 		return nil
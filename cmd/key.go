@@ -1,35 +1,56 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/utils"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+// keyAlgorithm is recorded for every project key
+const keyAlgorithm = "age-symmetric-v1"
+
 var (
-	keyExportProject string
-	keyExportAccept  bool
+	keyProject         string
+	keyExportAccept    bool
+	keyImportFile      string
+	keyImportForce     bool
+	keyShowFingerprint bool
+	keyRemoveYes       bool
 )
 
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Manage cached project keys",
+	Long: `Manage the project keys cached in keys.json. Subcommands act on the current
+project unless --project is given.`,
+}
+
 var keyExportCmd = &cobra.Command{
-	Use:   "key export",
+	Use:   "export",
 	Short: "Export project key for sharing",
 	Long:  `Export the current project's key in base64 format for sharing with teammates. Only available in local mode.`,
+	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load project config
-		projectConfig, err := config.LoadProjectConfig()
+		projectConfig, err := readProjectConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+			return err
 		}
 
 		// Determine which project to export
-		projectName := keyExportProject
-		if projectName == "" {
-			projectName = projectConfig.ProjectName
-		}
+		projectName := keyProjectName(projectConfig)
 
 		// Check if this is a cloud project
 		if projectConfig.Mode == "cloud" && !keyExportAccept {
@@ -39,23 +60,277 @@ var keyExportCmd = &cobra.Command{
 		// Get project key
 		projectKey, err := config.GetProjectKey(projectName)
 		if err != nil {
-			return fmt.Errorf("no key found for project '%s'", projectName)
+			return err
 		}
 
 		// Print warning
-		fmt.Fprintf(os.Stderr, "⚠️  WARNING: This will expose your project key!\n")
-		fmt.Fprintf(os.Stderr, "   Only share this with trusted teammates.\n")
-		fmt.Fprintf(os.Stderr, "   Project: %s\n", projectName)
-		fmt.Fprintf(os.Stderr, "   Key ID: %s\n\n", projectKey.KeyID)
+		fmt.Fprintf(stderr, "⚠️  WARNING: This will expose your project key!\n")
+		fmt.Fprintf(stderr, "   Only share this with trusted teammates.\n")
+		fmt.Fprintf(stderr, "   Project: %s\n", projectName)
+		fmt.Fprintf(stderr, "   Key ID: %s\n\n", projectKey.KeyID)
 
 		// Output the key to stdout
+		if jsonOutput() {
+			result := newKeyResult(projectName, projectKey)
+			result.Key = projectKey.KeyB64
+			return writeJSON(result)
+		}
 		fmt.Print(projectKey.KeyB64)
 
 		return nil
 	},
 }
 
+var keyImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a project key shared by a teammate",
+	Long: `Import a project key into keys.json. The key is read from --file ("-" for
+stdin), then from the PROJECT_KEY environment variable, then from stdin when
+it is not a terminal. Both the plain output of 'key export' and its
+--output json form are accepted.`,
+	Example: `  secretsnap key import --file team.key
+  pbpaste | secretsnap key import
+  PROJECT_KEY=${{ secrets.PROJECT_KEY }} secretsnap key import`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectConfig, err := readProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		data, source, err := readKeyInput()
+		if err != nil {
+			return err
+		}
+
+		imported, err := parseKeyInput(data)
+		if err != nil {
+			return fmt.Errorf("invalid key from %s: %w", source, err)
+		}
+
+		projectName := keyProjectName(projectConfig)
+		if existing, err := config.GetProjectKey(projectName); err == nil {
+			if existing.KeyB64 == imported.KeyB64 {
+				humanf("✅ Key for '%s' is already imported\n", projectName)
+				if jsonOutput() {
+					return writeJSON(newKeyResult(projectName, existing))
+				}
+				return nil
+			}
+			if !keyImportForce {
+				return fmt.Errorf("a different key for '%s' is already cached. Bundles encrypted with it cannot be read after replacing it. Use --force to replace it", projectName)
+			}
+		}
+
+		if err := config.SaveProjectKey(projectName, imported); err != nil {
+			return fmt.Errorf("failed to save project key: %w", err)
+		}
+
+		humanf("✅ Imported key for '%s' from %s to %s\n", projectName, source, config.GetKeysConfigPath())
+		if jsonOutput() {
+			return writeJSON(newKeyResult(projectName, imported))
+		}
+		return nil
+	},
+}
+
+var keyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached project keys",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keys, err := config.LoadKeysConfig()
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(keys.Projects))
+		for name := range keys.Projects {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if jsonOutput() {
+			results := make([]keyResult, 0, len(names))
+			for _, name := range names {
+				key := keys.Projects[name]
+				results = append(results, newKeyResult(name, &key))
+			}
+			return writeJSON(results)
+		}
+
+		if len(names) == 0 {
+			humanf("No project keys in %s\n", config.GetKeysConfigPath())
+			return nil
+		}
+
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROJECT\tKEY ID\tALGORITHM\tCREATED")
+		for _, name := range names {
+			key := keys.Projects[name]
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, key.KeyID, key.Algorithm, key.CreatedAt.Format("2006-01-02 15:04"))
+		}
+		return w.Flush()
+	},
+}
+
+var keyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show a project key's details without revealing it",
+	Long: `Show the project, key ID, algorithm and creation time of a cached key. With
+--fingerprint, print only its SHA-256 fingerprint, which teammates can compare
+to check they hold the same key.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectConfig, err := readProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		projectName := keyProjectName(projectConfig)
+		projectKey, err := config.GetProjectKey(projectName)
+		if err != nil {
+			return err
+		}
+		result := newKeyResult(projectName, projectKey)
+
+		switch {
+		case jsonOutput():
+			return writeJSON(result)
+		case keyShowFingerprint:
+			fmt.Fprintln(os.Stdout, result.Fingerprint)
+		default:
+			fmt.Fprintf(stdout, "Project:     %s\n", result.Project)
+			fmt.Fprintf(stdout, "Key ID:      %s\n", result.KeyID)
+			fmt.Fprintf(stdout, "Algorithm:   %s\n", result.Algorithm)
+			if result.CreatedAt != nil {
+				fmt.Fprintf(stdout, "Created:     %s\n", result.CreatedAt.Format(time.RFC3339))
+			}
+			fmt.Fprintf(stdout, "Fingerprint: %s\n", result.Fingerprint)
+		}
+		return nil
+	},
+}
+
+var keyRemoveCmd = &cobra.Command{
+	Use:     "rm",
+	Aliases: []string{"remove"},
+	Short:   "Remove a project key from the cache",
+	Long: `Remove a project key from keys.json. Bundles encrypted with it cannot be
+decrypted on this machine until the key is imported again.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectConfig, err := readProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		projectName := keyProjectName(projectConfig)
+		if _, err := config.GetProjectKey(projectName); err != nil {
+			return err
+		}
+
+		if !keyRemoveYes && !utils.Confirm(fmt.Sprintf("Remove the key for '%s'? Export it first if it is not stored anywhere else", projectName)) {
+			return fmt.Errorf("aborted")
+		}
+
+		if err := config.DeleteProjectKey(projectName); err != nil {
+			return err
+		}
+		humanf("✅ Removed key for '%s'\n", projectName)
+		return nil
+	},
+}
+
 func init() {
-	keyExportCmd.Flags().StringVarP(&keyExportProject, "project", "", "", "Project name (defaults to current project)")
+	keyCmd.PersistentFlags().StringVarP(&keyProject, "project", "", "", "Project name (defaults to current project)")
 	keyExportCmd.Flags().BoolVarP(&keyExportAccept, "i-accept-risk", "", false, "Accept the risk of exporting cloud project keys")
+	keyImportCmd.Flags().StringVarP(&keyImportFile, "file", "f", "", "Read the key from a file (\"-\" for stdin)")
+	keyImportCmd.Flags().BoolVarP(&keyImportForce, "force", "", false, "Replace a different cached key for the project")
+	keyShowCmd.Flags().BoolVarP(&keyShowFingerprint, "fingerprint", "", false, "Print only the key's SHA-256 fingerprint")
+	keyRemoveCmd.Flags().BoolVarP(&keyRemoveYes, "yes", "y", false, "Do not ask for confirmation")
+
+	keyCmd.AddCommand(keyExportCmd)
+	keyCmd.AddCommand(keyImportCmd)
+	keyCmd.AddCommand(keyListCmd)
+	keyCmd.AddCommand(keyShowCmd)
+	keyCmd.AddCommand(keyRemoveCmd)
+}
+
+// keyProjectName returns --project, or the current project's name
+func keyProjectName(projectConfig *config.ProjectConfig) string {
+	if keyProject != "" {
+		return keyProject
+	}
+	return projectConfig.ProjectName
+}
+
+// newKeyResult describes a cached key without including it
+func newKeyResult(projectName string, key *config.ProjectKey) keyResult {
+	result := keyResult{
+		Project:   projectName,
+		KeyID:     key.KeyID,
+		Algorithm: key.Algorithm,
+	}
+	if !key.CreatedAt.IsZero() {
+		result.CreatedAt = &key.CreatedAt
+	}
+	if keyBytes, err := crypto.KeyFromBase64(key.KeyB64); err == nil {
+		result.Fingerprint = crypto.KeyFingerprint(keyBytes)
+	}
+	return result
+}
+
+// readKeyInput reads a key to import and describes where it came from
+func readKeyInput() ([]byte, string, error) {
+	switch {
+	case keyImportFile == "-":
+		data, err := io.ReadAll(os.Stdin)
+		return data, "stdin", err
+	case keyImportFile != "":
+		data, err := os.ReadFile(keyImportFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read key file: %w", err)
+		}
+		return data, keyImportFile, nil
+	case os.Getenv("PROJECT_KEY") != "":
+		return []byte(os.Getenv("PROJECT_KEY")), "PROJECT_KEY", nil
+	case !term.IsTerminal(int(os.Stdin.Fd())):
+		data, err := io.ReadAll(os.Stdin)
+		return data, "stdin", err
+	}
+	return nil, "", withExitCode(exitUsage, fmt.Errorf("no key given. Use --file, set PROJECT_KEY, or pipe the key to stdin"))
+}
+
+// parseKeyInput accepts a base64 key or the JSON printed by
+// `key export --output json`
+func parseKeyInput(data []byte) (*config.ProjectKey, error) {
+	input := strings.TrimSpace(string(data))
+	if input == "" {
+		return nil, fmt.Errorf("the key is empty")
+	}
+	key := &config.ProjectKey{KeyB64: input, Algorithm: keyAlgorithm, CreatedAt: time.Now()}
+
+	if strings.HasPrefix(input, "{") {
+		var exported keyResult
+		if err := json.Unmarshal([]byte(input), &exported); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		key.KeyB64, key.KeyID = exported.Key, exported.KeyID
+		if exported.Algorithm != "" {
+			key.Algorithm = exported.Algorithm
+		}
+	}
+
+	if _, err := crypto.KeyFromBase64(key.KeyB64); err != nil {
+		return nil, err
+	}
+	if key.KeyID == "" {
+		keyID, err := crypto.GenerateKeyID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key ID: %w", err)
+		}
+		key.KeyID = keyID
+	}
+	return key, nil
 }
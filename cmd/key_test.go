@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseKeyInput(t *testing.T) {
+	const keyB64 = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+
+	tests := []struct {
+		name    string
+		input   string
+		wantID  string
+		wantErr string
+	}{
+		{"base64", keyB64 + "\n", "", ""},
+		{"export json", `{"project":"app","key_id":"abc","algorithm":"age-symmetric-v1","key":"` + keyB64 + `"}`, "abc", ""},
+		{"empty", "\n", "", "empty"},
+		{"short key", "AAAA", "", "32 bytes"},
+		{"bad json", "{", "", "JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := parseKeyInput([]byte(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseKeyInput() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseKeyInput() error = %v", err)
+			}
+			if key.KeyB64 != keyB64 || key.KeyID == "" || key.Algorithm != keyAlgorithm {
+				t.Errorf("parseKeyInput() = %+v", key)
+			}
+			if tt.wantID != "" && key.KeyID != tt.wantID {
+				t.Errorf("KeyID = %q, want %q", key.KeyID, tt.wantID)
+			}
+		})
+	}
+}
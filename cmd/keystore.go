@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/utils"
+
+	"filippo.io/age"
+	"github.com/spf13/cobra"
+)
+
+// Passphrase variables read by the keystore commands instead of prompting
+const (
+	keystorePassphraseEnv    = "SECRETSNAP_KEYSTORE_PASSPHRASE"
+	keystoreNewPassphraseEnv = "SECRETSNAP_KEYSTORE_NEW_PASSPHRASE"
+)
+
+var (
+	keystoreLockPass     utils.PassphraseSource
+	keystoreLockIdentity string
+
+	keystoreUnlockPass     utils.PassphraseSource
+	keystoreUnlockIdentity string
+	keystoreUnlockTimeout  time.Duration
+
+	keystorePasswdPass        utils.PassphraseSource
+	keystorePasswdIdentity    string
+	keystorePasswdNewPass     utils.PassphraseSource
+	keystorePasswdNewIdentity string
+)
+
+var keystoreCmd = &cobra.Command{
+	Use:   "keystore",
+	Short: "Encrypt the local key store with a master passphrase or identity",
+	Long: `Encrypt keys.json with a master passphrase or an age identity, so a backup or
+a stolen laptop does not leak the cached project keys.
+
+Once encrypted, commands that need a project key fail until the store is
+unlocked. An unlock lasts for the session: it is cached in $XDG_RUNTIME_DIR,
+which is not persisted, until the timeout or 'keystore lock'. Without it,
+unlock refuses to cache anything; set SECRETSNAP_IDENTITY to an identity file
+instead, which opens the store without unlocking it.`,
+	Example: `  # Encrypt the key store, then unlock it for the day
+  secretsnap keystore lock
+  secretsnap keystore unlock --timeout 10h
+
+  # Use an age identity instead of a passphrase
+  age-keygen -o ~/.config/secretsnap/master.txt
+  secretsnap keystore lock --identity ~/.config/secretsnap/master.txt`,
+}
+
+var keystoreStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the key store is encrypted and unlocked",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := config.GetKeystoreState()
+		if err != nil {
+			return err
+		}
+		if jsonOutput() {
+			return writeJSON(newKeystoreResult(state))
+		}
+
+		fmt.Fprintf(stdout, "Key store: %s\n", config.GetKeysConfigPath())
+		switch {
+		case !state.Encrypted:
+			fmt.Fprintf(stdout, "Encrypted: no\n")
+		case state.Unlocked:
+			fmt.Fprintf(stdout, "Encrypted: yes\n")
+			fmt.Fprintf(stdout, "Unlocked:  until %s\n", state.Expires.Format("2006-01-02 15:04"))
+		default:
+			fmt.Fprintf(stdout, "Encrypted: yes\n")
+			fmt.Fprintf(stdout, "Unlocked:  no\n")
+		}
+		return nil
+	},
+}
+
+var keystoreLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Encrypt the key store, or end the unlock session",
+	Long: `Encrypt a plaintext key store with a master passphrase, or with the public key
+of an age identity when --identity is given. The passphrase comes from the
+--pass flags or SECRETSNAP_KEYSTORE_PASSPHRASE, and is prompted for twice
+otherwise. keys.json is replaced atomically.
+
+When the store is already encrypted, end the unlock session.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := config.GetKeystoreState()
+		if err != nil {
+			return err
+		}
+
+		if state.Encrypted {
+			if err := config.LockKeystore(); err != nil {
+				return err
+			}
+			humanf("🔒 Locked the key store\n")
+			if jsonOutput() {
+				return writeJSON(newKeystoreResult(&config.KeystoreState{Encrypted: true}))
+			}
+			return nil
+		}
+
+		master, err := keystoreMasterRecipient(keystoreLockIdentity, keystoreLockPass)
+		if err != nil {
+			return err
+		}
+		if err := config.EncryptKeystore(master); err != nil {
+			return err
+		}
+
+		humanf("🔒 Encrypted %s\n", config.GetKeysConfigPath())
+		humanf("💡 Run 'secretsnap keystore unlock' before using project keys\n")
+		if jsonOutput() {
+			return writeJSON(newKeystoreResult(&config.KeystoreState{Encrypted: true}))
+		}
+		return nil
+	},
+}
+
+var keystoreUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlock the key store for this session",
+	Long: `Open the key store with the master passphrase, or with the identity file given
+by --identity or SECRETSNAP_IDENTITY, and keep it unlocked until --timeout
+passes or 'keystore lock' is run. The passphrase comes from the --pass flags
+or SECRETSNAP_KEYSTORE_PASSPHRASE, and is prompted for otherwise.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if keystoreUnlockTimeout <= 0 {
+			return withExitCode(exitUsage, fmt.Errorf("--timeout must be positive"))
+		}
+
+		identityFile := keystoreUnlockIdentity
+		if identityFile == "" && !keystoreUnlockPass.Explicit() {
+			identityFile = os.Getenv(config.IdentityEnv)
+		}
+		master, err := keystoreMasterIdentity(identityFile, keystoreUnlockPass)
+		if err != nil {
+			return err
+		}
+
+		expires, err := config.UnlockKeystore(master, keystoreUnlockTimeout)
+		if err != nil {
+			return err
+		}
+
+		humanf("🔓 Unlocked the key store until %s\n", expires.Format("2006-01-02 15:04"))
+		if jsonOutput() {
+			return writeJSON(newKeystoreResult(&config.KeystoreState{Encrypted: true, Unlocked: true, Expires: expires}))
+		}
+		return nil
+	},
+}
+
+var keystorePasswdCmd = &cobra.Command{
+	Use:   "passwd",
+	Short: "Change the key store's master passphrase or identity",
+	Long: `Change the master of an encrypted key store. The current master is given like
+for unlock; the new one is a passphrase from the --new-pass flags or
+SECRETSNAP_KEYSTORE_NEW_PASSPHRASE (prompted for twice otherwise), or the
+public key of the identity in --new-identity. The project keys are
+re-encrypted to a new store identity, and the unlock session ends.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if keystorePasswdPass.Stdin && keystorePasswdNewPass.Stdin {
+			return withExitCode(exitUsage, fmt.Errorf("only one passphrase can be read from stdin"))
+		}
+
+		old, err := keystoreMasterIdentity(keystorePasswdIdentity, keystorePasswdPass)
+		if err != nil {
+			return err
+		}
+		master, err := keystoreMasterRecipient(keystorePasswdNewIdentity, keystorePasswdNewPass)
+		if err != nil {
+			return err
+		}
+
+		if err := config.ChangeKeystoreMaster(old, master); err != nil {
+			return err
+		}
+
+		humanf("✅ Changed the key store's master\n")
+		humanf("💡 Run 'secretsnap keystore unlock' with the new master to unlock it again\n")
+		return nil
+	},
+}
+
+func init() {
+	addPassSourceFlags(keystoreLockCmd, &keystoreLockPass, keystorePassphraseEnv)
+	keystoreLockCmd.Flags().StringVarP(&keystoreLockIdentity, "identity", "i", "", "Encrypt to the public key of this age identity file instead of a passphrase")
+
+	addPassSourceFlags(keystoreUnlockCmd, &keystoreUnlockPass, keystorePassphraseEnv)
+	keystoreUnlockCmd.Flags().StringVarP(&keystoreUnlockIdentity, "identity", "i", "", "Unlock with this age identity file")
+	keystoreUnlockCmd.Flags().DurationVarP(&keystoreUnlockTimeout, "timeout", "t", 8*time.Hour, "How long the store stays unlocked")
+
+	addPassSourceFlags(keystorePasswdCmd, &keystorePasswdPass, keystorePassphraseEnv)
+	keystorePasswdCmd.Flags().StringVarP(&keystorePasswdIdentity, "identity", "i", "", "Current master identity file")
+	addNewPassFlags(keystorePasswdCmd, &keystorePasswdNewPass, keystoreNewPassphraseEnv)
+	keystorePasswdCmd.Flags().StringVarP(&keystorePasswdNewIdentity, "new-identity", "", "", "Encrypt to the public key of this age identity file instead of a passphrase")
+
+	keystoreCmd.AddCommand(keystoreStatusCmd)
+	keystoreCmd.AddCommand(keystoreLockCmd)
+	keystoreCmd.AddCommand(keystoreUnlockCmd)
+	keystoreCmd.AddCommand(keystorePasswdCmd)
+}
+
+// keystoreMasterRecipient returns the recipient a key store is encrypted
+// for: the identity file's public key, or a new passphrase
+func keystoreMasterRecipient(identityFile string, src utils.PassphraseSource) (age.Recipient, error) {
+	if identityFile != "" {
+		if src.Explicit() {
+			return nil, withExitCode(exitUsage, fmt.Errorf("use either an identity file or a passphrase, not both"))
+		}
+		identity, err := crypto.ReadIdentityFile(identityFile)
+		if err != nil {
+			return nil, err
+		}
+		return identity.Recipient(), nil
+	}
+
+	passphrase, err := utils.GetNewPassphrase(src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get master passphrase: %w", err)
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recipient: %w", err)
+	}
+	return recipient, nil
+}
+
+// keystoreMasterIdentity returns the identity that opens a key store: the
+// identity file, or the master passphrase
+func keystoreMasterIdentity(identityFile string, src utils.PassphraseSource) (age.Identity, error) {
+	if identityFile != "" {
+		if src.Explicit() {
+			return nil, withExitCode(exitUsage, fmt.Errorf("use either an identity file or a passphrase, not both"))
+		}
+		return crypto.ReadIdentityFile(identityFile)
+	}
+
+	passphrase, err := utils.GetPassphrase(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get master passphrase: %w", err)
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity: %w", err)
+	}
+	return identity, nil
+}
+
+// newKeystoreResult describes the key store's state
+func newKeystoreResult(state *config.KeystoreState) keystoreResult {
+	result := keystoreResult{Path: config.GetKeysConfigPath(), Encrypted: state.Encrypted, Unlocked: state.Unlocked}
+	if state.Unlocked {
+		result.Expires = &state.Expires
+	}
+	return result
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/utils"
+)
+
+// layer is one source of values in a composed environment
+type layer struct {
+	Name   string // as written in the layers config
+	Kind   string // "bundle", "file" or "process"
+	Path   string
+	config *config.ProjectConfig // key settings for bundle layers
+
+	File    *dotenv.File // nil for the process layer and skipped layers
+	Skipped string       // why the layer contributed nothing
+	raw     []byte       // decrypted bundle as stored
+}
+
+// projectLayers parses the configured layers. An explicit bundle path, or a
+// project without layers, makes a single bundle layer.
+func projectLayers(projectConfig *config.ProjectConfig, bundlePath string) ([]*layer, error) {
+	if bundlePath != "" || len(projectConfig.Layers) == 0 {
+		if bundlePath == "" {
+			bundlePath = defaultBundlePath(projectConfig)
+		}
+		return []*layer{{Name: "bundle", Kind: "bundle", Path: bundlePath, config: projectConfig}}, nil
+	}
+
+	base := projectConfig.Base()
+	var layers []*layer
+	for _, spec := range projectConfig.Layers {
+		kind, path, _ := strings.Cut(spec, ":")
+		l := &layer{Name: spec, Kind: kind, Path: projectConfig.Path(path)}
+
+		switch {
+		case spec == "base":
+			l.Kind, l.Path, l.config = "bundle", defaultBundlePath(base), base
+		case spec == "env":
+			l.Kind, l.Path, l.config = "bundle", defaultBundlePath(projectConfig), projectConfig
+			if projectConfig.Env == "" {
+				l.Skipped = "no environment selected"
+			} else if l.Path == defaultBundlePath(base) {
+				l.Skipped = "same bundle as base"
+			}
+		case spec == "process":
+		case kind == "bundle" && path != "":
+			l.config = base
+		case kind == "file" && path != "":
+		default:
+			return nil, fmt.Errorf("invalid layer '%s' in %s (use base, env, file:<path>, bundle:<path> or process)", spec, config.GetProjectConfigPath())
+		}
+
+		layers = append(layers, l)
+	}
+
+	return layers, nil
+}
+
+// composeEnv loads every layer and merges them, later layers winning. The
+// result starts from the first layer, so its comments and order are kept.
+// Bundle layers have their references resolved when resolve is set, and a
+// passphrase given by flag is read once for all of them. The returned mode
+// is that of the last bundle decrypted.
+func composeEnv(projectConfig *config.ProjectConfig, bundlePath string, src utils.PassphraseSource, passMode, resolve bool) (*dotenv.File, []*layer, string, error) {
+	layers, err := projectLayers(projectConfig, bundlePath)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	merged := &dotenv.File{}
+	mode := ""
+	for _, l := range layers {
+		if l.Skipped != "" {
+			continue
+		}
+
+		switch l.Kind {
+		case "bundle":
+			encryptedData, err := readBundleFile(l.Path)
+			if err != nil {
+				return nil, nil, "", err
+			}
+			key, err := resolveBundleKey(l.config, src, passMode)
+			if err != nil {
+				return nil, nil, "", fmt.Errorf("%s: %w", l.Path, err)
+			}
+			// --pass-stdin and --pass-fd can only be read once, so later
+			// layers reuse the passphrase
+			if key.mode == "passphrase" && src.Explicit() {
+				src = utils.PassphraseSource{Pass: key.passphrase, Env: src.Env}
+			}
+			decryptedData, err := key.decryptResolved(encryptedData)
+			if err != nil {
+				return nil, nil, "", fmt.Errorf("%s: %w", l.Path, err)
+			}
+			mode = key.mode
+
+			l.raw = decryptedData
+			l.File = dotenv.Parse(decryptedData)
+			if resolve {
+				if err := resolveReferences(l.File, l.Path, l.config); err != nil {
+					return nil, nil, "", err
+				}
+			}
+		case "file":
+			data, err := os.ReadFile(l.Path)
+			if os.IsNotExist(err) {
+				l.Skipped = "file not found"
+				continue
+			}
+			if err != nil {
+				return nil, nil, "", fmt.Errorf("failed to read %s: %w", l.Path, err)
+			}
+			l.File = dotenv.Parse(data)
+		case "process":
+			// Only keys already defined are taken from the process
+			// environment; the rest is inherited by child processes anyway
+			for _, key := range merged.Keys() {
+				if value, ok := os.LookupEnv(key); ok {
+					merged.Set(key, value)
+				}
+			}
+			continue
+		}
+
+		if len(merged.Lines) == 0 {
+			merged.Lines = append(merged.Lines, l.File.Lines...)
+			continue
+		}
+		values := l.File.Map()
+		for _, key := range l.File.Keys() {
+			merged.Set(key, values[key])
+		}
+		for _, meta := range l.File.AllMeta() {
+			merged.SetMeta(meta)
+		}
+	}
+
+	return merged, layers, mode, nil
+}
+
+// defines reports whether a loaded layer sets key
+func (l *layer) defines(key string) bool {
+	if l.Kind == "process" {
+		_, ok := os.LookupEnv(key)
+		return ok
+	}
+	if l.File == nil {
+		return false
+	}
+	_, ok := l.File.Get(key)
+	return ok
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/utils"
+)
+
+func TestComposeEnvFileLayers(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "shared.env")
+	local := filepath.Join(dir, "local.env")
+	os.WriteFile(shared, []byte("# shared\nA=1\nB=2\nC=3\n"), 0600)
+	os.WriteFile(local, []byte("B=local\nD=4\n"), 0600)
+	t.Setenv("C", "process")
+	t.Setenv("E", "ignored")
+
+	projectConfig := &config.ProjectConfig{Layers: []string{
+		"file:" + shared,
+		"file:" + local,
+		"file:" + filepath.Join(dir, "missing.env"),
+		"process",
+	}}
+
+	env, layers, _, err := composeEnv(projectConfig, "", utils.PassphraseSource{}, false, false)
+	if err != nil {
+		t.Fatalf("composeEnv() error = %v", err)
+	}
+
+	want := map[string]string{"A": "1", "B": "local", "C": "process", "D": "4"}
+	got := env.Map()
+	if len(got) != len(want) {
+		t.Errorf("composeEnv() = %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+	if layers[2].Skipped == "" {
+		t.Errorf("missing file layer was not skipped")
+	}
+}
+
+func TestProjectLayersInvalid(t *testing.T) {
+	for _, spec := range []string{"bundle:", "file:", "dotenv", "local"} {
+		projectConfig := &config.ProjectConfig{Layers: []string{spec}}
+		if _, err := projectLayers(projectConfig, ""); err == nil {
+			t.Errorf("projectLayers(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func TestComposeEnvReadsPassphraseOnce(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.envsnap": "A=1\n", "b.envsnap": "B=2\n"} {
+		encryptedData, err := crypto.EncryptWithPassphrase([]byte(content), "correct horse battery", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(filepath.Join(dir, name), encryptedData, 0600)
+	}
+
+	// A file descriptor can only be read once
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("correct horse battery\n")
+	w.Close()
+	defer r.Close()
+
+	projectConfig := &config.ProjectConfig{Dir: dir, Mode: "passphrase", Layers: []string{"bundle:a.envsnap", "bundle:b.envsnap"}}
+	env, _, _, err := composeEnv(projectConfig, "", utils.PassphraseSource{FD: int(r.Fd())}, false, false)
+	if err != nil {
+		t.Fatalf("composeEnv() error = %v", err)
+	}
+	if got := env.Map(); got["A"] != "1" || got["B"] != "2" {
+		t.Errorf("composeEnv() = %v, want A and B", got)
+	}
+}
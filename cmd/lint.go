@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintPass   passFlags
+	lintFix    bool
+	lintFormat string
+)
+
+// lintReport is the machine-readable output of `lint --format json`
+type lintReport struct {
+	File   string         `json:"file"`
+	Bundle bool           `json:"bundle"`
+	Fixed  int            `json:"fixed"`
+	Issues []dotenv.Issue `json:"issues"`
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [path-to-.env-or-bundle]",
+	Short: "Check a .env file or bundle for common mistakes",
+	Long: `Check a plaintext .env file or an encrypted bundle for duplicate keys, invalid
+variable names, stray whitespace, unquoted values with spaces, mixed export
+lines, CRLF endings and lines that 'run' would silently drop.
+
+Bundles are decrypted in memory only. Use --fix to rewrite the safe cases in a
+plaintext file, and --format json for machine-readable output.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputFile := ".env"
+		if len(args) == 1 {
+			inputFile = args[0]
+		}
+
+		if lintFormat != "text" && lintFormat != "json" {
+			return fmt.Errorf("format must be 'text' or 'json', got '%s'", lintFormat)
+		}
+
+		if _, err := os.Stat(inputFile); os.IsNotExist(err) {
+			return fmt.Errorf("input file '%s' does not exist", inputFile)
+		}
+
+		data, err := os.ReadFile(inputFile)
+		if err != nil {
+			return fmt.Errorf("failed to read input file: %w", err)
+		}
+
+		isBundle := crypto.IsBundle(data)
+		if isBundle {
+			if lintFix {
+				return fmt.Errorf("--fix only works on plaintext files. Unbundle, fix, and bundle again")
+			}
+
+			projectConfig, err := loadProjectConfig()
+			if err != nil {
+				return err
+			}
+
+			data, _, err = decryptBundle(data, projectConfig, lintPass.PassphraseSource, lintPass.mode)
+			if err != nil {
+				return err
+			}
+		}
+
+		report := lintReport{File: inputFile, Bundle: isBundle}
+
+		if lintFix {
+			fixed, changed := dotenv.Fix(data)
+			if changed > 0 {
+				if err := os.WriteFile(inputFile, fixed, 0600); err != nil {
+					return fmt.Errorf("failed to write fixed file: %w", err)
+				}
+			}
+			report.Fixed = changed
+			data = fixed
+		}
+
+		report.Issues = dotenv.Lint(data)
+		if report.Issues == nil {
+			report.Issues = []dotenv.Issue{}
+		}
+
+		if lintFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return fmt.Errorf("failed to encode report: %w", err)
+			}
+		} else {
+			printLintReport(report)
+		}
+
+		if len(report.Issues) > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d issue(s) found in %s", len(report.Issues), inputFile)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addPassFlags(lintCmd, &lintPass)
+	lintCmd.Flags().BoolVarP(&lintFix, "fix", "", false, "Rewrite fixable issues in a plaintext file")
+	lintCmd.Flags().StringVarP(&lintFormat, "format", "", "text", "Output format (text|json)")
+}
+
+// printLintReport prints lint issues for humans
+func printLintReport(report lintReport) {
+	if report.Fixed > 0 {
+		fmt.Fprintf(stdout, "🔧 Fixed %d line(s) in %s\n", report.Fixed, report.File)
+	}
+
+	if len(report.Issues) == 0 {
+		fmt.Fprintf(stdout, "✅ No issues found in %s\n", report.File)
+		return
+	}
+
+	for _, issue := range report.Issues {
+		icon := "⚠️ "
+		if issue.Severity == dotenv.SeverityError {
+			icon = "❌"
+		}
+		fixHint := ""
+		if issue.Fixable && !report.Bundle {
+			fixHint = " (fixable with --fix)"
+		}
+		fmt.Fprintf(stdout, "%s %s:%d [%s] %s%s\n", icon, report.File, issue.Line, issue.Code, issue.Message, fixHint)
+	}
+}
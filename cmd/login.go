@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"secretsnap/internal/api"
@@ -34,31 +35,43 @@ var loginCmd = &cobra.Command{
 		// Login
 		resp, err := client.Login(loginLicense)
 		if err != nil {
-			return fmt.Errorf("login failed: %v", err)
+			return fmt.Errorf("login failed: %w", err)
 		}
 
 		// Save token
 		if err := config.SaveToken(resp.Token); err != nil {
-			return fmt.Errorf("failed to save token: %v", err)
+			return fmt.Errorf("failed to save token: %w", err)
 		}
 
-		// Update project config to cloud mode
-		projectConfig, err := config.LoadProjectConfig()
-		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+		// Switch the current project, if any, to cloud mode. Outside a
+		// project only the token is saved.
+		mode := ""
+		projectConfig, err := config.FindProjectConfig()
+		switch {
+		case err == nil:
+			projectConfig.Mode = "cloud"
+			if err := config.SaveProjectConfig(projectConfig); err != nil {
+				return fmt.Errorf("failed to save project config: %w", err)
+			}
+			mode = projectConfig.Mode
+		case !errors.Is(err, config.ErrNoProjectConfig):
+			return fmt.Errorf("failed to load project config: %w", err)
 		}
 
-		projectConfig.Mode = "cloud"
-		if err := config.SaveProjectConfig(projectConfig); err != nil {
-			return fmt.Errorf("failed to save project config: %v", err)
+		humanf("✅ Logged in successfully!\n")
+		humanf("👤 User: %s\n", resp.User.Email)
+		humanf("📋 Plan: %s\n", resp.User.Plan)
+		if mode != "" {
+			humanf("🔧 Mode: %s\n", mode)
+		}
+		humanf("🔑 Token saved to: %s\n", config.GetTokenPath())
+		if mode == "" {
+			humanf("💡 No project here. Run 'secretsnap init' or 'secretsnap project create' in your project\n")
 		}
 
-		fmt.Printf("✅ Logged in successfully!\n")
-		fmt.Printf("👤 User: %s\n", resp.User.Email)
-		fmt.Printf("📋 Plan: %s\n", resp.User.Plan)
-		fmt.Printf("🔧 Mode: %s\n", projectConfig.Mode)
-		fmt.Printf("🔑 Token saved to: %s\n", config.GetKeysConfigPath())
-
+		if jsonOutput() {
+			return writeJSON(loginResult{Email: resp.User.Email, Plan: resp.User.Plan, Mode: mode, TokenFile: config.GetTokenPath()})
+		}
 		return nil
 	},
 }
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"secretsnap/internal/api"
+	"secretsnap/internal/config"
+	"secretsnap/internal/utils"
+)
+
+// outputFormat is the global --output flag
+var outputFormat string
+
+// quietFlag and plainFlag are the global --quiet and --plain flags
+var (
+	quietFlag bool
+	plainFlag bool
+)
+
+// quiet suppresses progress messages and upsell banners
+var quiet bool
+
+// stdout and stderr carry reports and messages. Command data (secret
+// values, keys, rendered files, JSON) is written to os.Stdout directly so
+// that it is never altered.
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// configureOutput applies --quiet and --plain, their environment variables
+// (SECRETSNAP_QUIET, NO_COLOR, CI) and the saved settings
+func configureOutput() {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: ignoring settings: %v\n", err)
+		settings = &config.Settings{}
+	}
+
+	quiet = quietFlag || envEnabled("SECRETSNAP_QUIET") || settings.Quiet
+	utils.UpsellDisabled = quiet || settings.NoUpsell || envEnabled("CI")
+
+	if plainFlag || os.Getenv("NO_COLOR") != "" || settings.Plain {
+		stdout = &utils.PlainWriter{W: os.Stdout}
+		stderr = &utils.PlainWriter{W: os.Stderr}
+		utils.UpsellOutput = stderr
+	}
+}
+
+// envEnabled reports whether a boolean environment variable is set to
+// anything but "", "0" or "false"
+func envEnabled(name string) bool {
+	value := strings.ToLower(os.Getenv(name))
+	return value != "" && value != "0" && value != "false"
+}
+
+// jsonOutput reports whether the command should print JSON on stdout
+func jsonOutput() bool {
+	return outputFormat == "json"
+}
+
+// humanf prints progress messages. With --output json they go to stderr
+// so that stdout only carries the JSON result; --quiet drops them.
+func humanf(format string, args ...interface{}) {
+	switch {
+	case quiet:
+	case jsonOutput():
+		fmt.Fprintf(stderr, format, args...)
+	default:
+		fmt.Fprintf(stdout, format, args...)
+	}
+}
+
+// writeJSON prints a command result to stdout
+func writeJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return nil
+}
+
+// Results printed by --output json. Field names are part of the CLI's
+// interface: add fields, never rename or remove them.
+type (
+	initResult struct {
+		Project    string `json:"project"`
+		Mode       string `json:"mode"`
+		KeyID      string `json:"key_id"`
+		ConfigFile string `json:"config_file"`
+		KeysFile   string `json:"keys_file"`
+		Created    bool   `json:"created"`
+	}
+
+	bundleResult struct {
+		Input    string `json:"input"`
+		Output   string `json:"output,omitempty"`
+		Mode     string `json:"mode"`
+		Project  string `json:"project,omitempty"`
+		BundleID string `json:"bundle_id,omitempty"`
+		Version  int    `json:"version,omitempty"`
+	}
+
+	unbundleResult struct {
+		Input  string   `json:"input,omitempty"`
+		Layers []string `json:"layers,omitempty"`
+		Output string   `json:"output"`
+		Mode   string   `json:"mode"`
+	}
+
+	pullResult struct {
+		ProjectID string `json:"project_id"`
+		Version   int    `json:"version"`
+		Output    string `json:"output"`
+	}
+
+	projectResult struct {
+		ProjectID string `json:"project_id"`
+		Name      string `json:"name"`
+		Mode      string `json:"mode"`
+	}
+
+	shareResult struct {
+		Project string `json:"project"`
+		User    string `json:"user"`
+		Role    string `json:"role"`
+	}
+
+	auditResult struct {
+		Project string         `json:"project"`
+		Logs    []api.AuditLog `json:"logs"`
+	}
+
+	keyResult struct {
+		Project     string     `json:"project"`
+		KeyID       string     `json:"key_id"`
+		Algorithm   string     `json:"algorithm"`
+		Key         string     `json:"key,omitempty"`
+		CreatedAt   *time.Time `json:"created_at,omitempty"`
+		Fingerprint string     `json:"fingerprint,omitempty"`
+	}
+
+	verifyResult struct {
+		Bundle string `json:"bundle"`
+		Mode   string `json:"mode"`
+		Keys   int    `json:"keys"`
+	}
+
+	rekeyResult struct {
+		Bundle string `json:"bundle"`
+		From   string `json:"from"`
+		To     string `json:"to"`
+	}
+
+	keystoreResult struct {
+		Path      string     `json:"path"`
+		Encrypted bool       `json:"encrypted"`
+		Unlocked  bool       `json:"unlocked"`
+		Expires   *time.Time `json:"expires,omitempty"`
+	}
+
+	loginResult struct {
+		Email     string `json:"email"`
+		Plan      string `json:"plan"`
+		Mode      string `json:"mode"`
+		TokenFile string `json:"token_file"`
+	}
+)
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"secretsnap/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// newPassphraseEnv is read by rekey when no --new-pass flag is given
+const newPassphraseEnv = "SECRETSNAP_NEW_PASSPHRASE"
+
+// passFlags are the passphrase flags of a command that reads or writes
+// bundles
+type passFlags struct {
+	utils.PassphraseSource
+	mode bool // --pass-mode
+}
+
+// addPassFlags registers --pass, --pass-file, --pass-stdin, --pass-fd,
+// --pass-cmd and --pass-mode
+func addPassFlags(cmd *cobra.Command, f *passFlags) {
+	addPassSourceFlags(cmd, &f.PassphraseSource, utils.PassphraseEnv)
+	cmd.Flags().BoolVarP(&f.mode, "pass-mode", "", false, "Use passphrase mode (SECRETSNAP_PASSPHRASE, or prompt)")
+}
+
+// addPassSourceFlags registers the --pass flags that fill src, and env as
+// the variable read instead of prompting
+func addPassSourceFlags(cmd *cobra.Command, src *utils.PassphraseSource, env string) {
+	src.Env = env
+	cmd.Flags().StringVarP(&src.Pass, "pass", "p", "", "Passphrase (visible in ps and shell history, prefer the other sources)")
+	cmd.Flags().StringVarP(&src.File, "pass-file", "", "", "Read passphrase from file")
+	cmd.Flags().BoolVarP(&src.Stdin, "pass-stdin", "", false, "Read passphrase from stdin")
+	cmd.Flags().IntVarP(&src.FD, "pass-fd", "", 0, "Read passphrase from file descriptor N")
+	cmd.Flags().StringVarP(&src.Cmd, "pass-cmd", "", "", "Read passphrase from the first line a command prints (e.g. \"pass show team/app\")")
+}
+
+// addNewPassFlags registers the --new-pass flags for a passphrase that
+// replaces the current one, and env as the variable read instead of
+// prompting
+func addNewPassFlags(cmd *cobra.Command, src *utils.PassphraseSource, env string) {
+	src.Env = env
+	cmd.Flags().StringVarP(&src.Pass, "new-pass", "", "", "New passphrase (visible in ps and shell history)")
+	cmd.Flags().StringVarP(&src.File, "new-pass-file", "", "", "Read the new passphrase from file")
+	cmd.Flags().BoolVarP(&src.Stdin, "new-pass-stdin", "", false, "Read the new passphrase from stdin")
+	cmd.Flags().IntVarP(&src.FD, "new-pass-fd", "", 0, "Read the new passphrase from file descriptor N")
+	cmd.Flags().StringVarP(&src.Cmd, "new-pass-cmd", "", "", "Read the new passphrase from the first line a command prints")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"secretsnap/internal/api"
@@ -19,24 +20,30 @@ var projectCmd = &cobra.Command{
 var projectCreateCmd = &cobra.Command{
 	Use:   "create [name]",
 	Short: "Create a new project",
-	Long:  `Create a new project for team collaboration.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new project for team collaboration and switch the current project
+to it. Without a .secretsnap.json, one is created in the project directory once
+the project exists.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		projectName := args[0]
 
-		// Load project config and token
-		projectConfig, err := config.LoadProjectConfig()
+		// Load project config and token. Without a project config, one is
+		// only written once the project has been created.
+		projectConfig, err := config.FindProjectConfig()
+		if errors.Is(err, config.ErrNoProjectConfig) {
+			projectConfig, err = config.DefaultProjectConfig(config.ProjectDir()), nil
+		}
 		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+			return fmt.Errorf("failed to load project config: %w", err)
 		}
 
 		token, err := config.LoadToken()
 		if err != nil {
-			return fmt.Errorf("failed to load token: %v", err)
+			return fmt.Errorf("failed to load token: %w", err)
 		}
 
 		if token == "" {
-			return fmt.Errorf("not logged in. Run 'secretsnap login --license <KEY>' first")
+			return fmt.Errorf("%w. Run 'secretsnap login --license <KEY>' first", config.ErrNotLoggedIn)
 		}
 
 		// Create API client
@@ -45,7 +52,7 @@ var projectCreateCmd = &cobra.Command{
 		// Create project
 		project, err := client.CreateProject(projectName)
 		if err != nil {
-			return fmt.Errorf("failed to create project: %v", err)
+			return fmt.Errorf("failed to create project: %w", err)
 		}
 
 		// Update project config
@@ -53,14 +60,17 @@ var projectCreateCmd = &cobra.Command{
 		projectConfig.ProjectID = project.ID
 		projectConfig.Mode = "cloud"
 		if err := config.SaveProjectConfig(projectConfig); err != nil {
-			return fmt.Errorf("failed to save project config: %v", err)
+			return fmt.Errorf("failed to save project config: %w", err)
 		}
 
-		fmt.Printf("✅ Project created successfully!\n")
-		fmt.Printf("📦 Project ID: %s\n", project.ID)
-		fmt.Printf("📝 Name: %s\n", project.Name)
-		fmt.Printf("🔧 Mode: %s\n", projectConfig.Mode)
+		humanf("✅ Project created successfully!\n")
+		humanf("📦 Project ID: %s\n", project.ID)
+		humanf("📝 Name: %s\n", project.Name)
+		humanf("🔧 Mode: %s\n", projectConfig.Mode)
 
+		if jsonOutput() {
+			return writeJSON(projectResult{ProjectID: project.ID, Name: project.Name, Mode: projectConfig.Mode})
+		}
 		return nil
 	},
 }
@@ -25,19 +25,23 @@ var pullCmd = &cobra.Command{
 	Short: "Pull latest bundle from cloud",
 	Long:  `Download and decrypt the latest bundle from the cloud project.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if workspaceAll {
+			return runWorkspace(cmd, args, nil)
+		}
+
 		// Load project config and token
-		projectConfig, err := config.LoadProjectConfig()
+		projectConfig, err := readProjectConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+			return err
 		}
 
 		token, err := config.LoadToken()
 		if err != nil {
-			return fmt.Errorf("failed to load token: %v", err)
+			return fmt.Errorf("failed to load token: %w", err)
 		}
 
 		if token == "" {
-			return fmt.Errorf("not logged in. Run 'secretsnap login --license <KEY>' first")
+			return fmt.Errorf("%w. Run 'secretsnap login --license <KEY>' first", config.ErrNotLoggedIn)
 		}
 
 		// Use project from config if not specified
@@ -61,25 +65,25 @@ var pullCmd = &cobra.Command{
 			resp, bundlePullErr = client.BundlePull(pullProject)
 		}
 		if bundlePullErr != nil {
-			return fmt.Errorf("failed to pull bundle: %v", bundlePullErr)
+			return fmt.Errorf("failed to pull bundle: %w", bundlePullErr)
 		}
 
 		// Download encrypted data
 		encryptedData, err := client.DownloadFromAPI(resp.DownloadURL)
 		if err != nil {
-			return fmt.Errorf("failed to download bundle: %v", err)
+			return fmt.Errorf("failed to download bundle: %w", err)
 		}
 
 		// Decode data key
 		dataKey, err := base64.StdEncoding.DecodeString(resp.DataKey)
 		if err != nil {
-			return fmt.Errorf("failed to decode data key: %v", err)
+			return fmt.Errorf("failed to decode data key: %w", err)
 		}
 
 		// Decrypt data
 		decryptedData, err := crypto.DecryptWithKey(encryptedData, dataKey)
 		if err != nil {
-			return fmt.Errorf("failed to decrypt bundle: %v", err)
+			return fmt.Errorf("failed to decrypt bundle: %w", err)
 		}
 
 		// Check if output file exists and handle --force
@@ -89,22 +93,27 @@ var pullCmd = &cobra.Command{
 
 		// Write output file with secure permissions
 		if err := os.WriteFile(pullOutFile, decryptedData, 0600); err != nil {
-			return fmt.Errorf("failed to write output file: %v", err)
+			return fmt.Errorf("failed to write output file: %w", err)
 		}
 
 		// Check if file permissions are correct and warn if not
 		if info, err := os.Stat(pullOutFile); err == nil {
 			if info.Mode().Perm() != 0600 {
-				fmt.Printf("⚠️  Warning: %s has permissions %v, should be 0600\n", pullOutFile, info.Mode().Perm())
+				humanf("⚠️  Warning: %s has permissions %v, should be 0600\n", pullOutFile, info.Mode().Perm())
 			}
 		}
 
-		fmt.Printf("✅ Pulled version %d to %s\n", resp.Version, pullOutFile)
+		humanf("✅ Pulled version %d to %s\n", resp.Version, pullOutFile)
+		recordRemoteVersion(pullProject, resp.Version)
 
 		// Show feature-specific upsell for cloud features
 		if err := utils.ShowFeatureUpsell("cloud"); err != nil {
 			// Don't fail the command if upsell fails
-			fmt.Fprintf(os.Stderr, "Warning: failed to show upsell: %v\n", err)
+			fmt.Fprintf(stderr, "Warning: failed to show upsell: %v\n", err)
+		}
+
+		if jsonOutput() {
+			return writeJSON(pullResult{ProjectID: pullProject, Version: resp.Version, Output: pullOutFile})
 		}
 
 		return nil
@@ -116,4 +125,5 @@ func init() {
 	pullCmd.Flags().StringVarP(&pullProject, "project", "", "", "Project ID or name")
 	pullCmd.Flags().IntVarP(&pullVersion, "version", "", 0, "Specific version to pull")
 	pullCmd.Flags().BoolVarP(&pullForce, "force", "f", false, "Overwrite output file if it exists")
+	addWorkspaceFlags(pullCmd)
 }
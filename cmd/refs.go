@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/refs"
+)
+
+// resolveReferences replaces secretsnap:// and ref: values in env, which was
+// decrypted from bundlePath, with the values they point at. Referenced
+// bundles are decrypted with their own project's cached key.
+func resolveReferences(env *dotenv.File, bundlePath string, projectConfig *config.ProjectConfig) error {
+	path, err := filepath.Abs(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve bundle path: %w", err)
+	}
+
+	resolver := &refs.Resolver{
+		Locate: func(ref *refs.Ref, from refs.Location) (refs.Location, error) {
+			return locateReference(ref, from, projectConfig)
+		},
+		Open: openReferencedBundle,
+	}
+
+	return resolver.Resolve(env, refs.Location{Path: path, Project: projectConfig.ProjectName})
+}
+
+// locateReference finds the bundle a reference points at. ref: paths are
+// relative to the referencing bundle; secretsnap:// projects are looked up
+// in the "projects" map of .secretsnap.json, and the bundle is the one the
+// project configures for the environment, or <env>.envsnap when it declares
+// no such environment.
+func locateReference(ref *refs.Ref, from refs.Location, projectConfig *config.ProjectConfig) (refs.Location, error) {
+	if ref.Path != "" {
+		path := ref.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(from.Path), path)
+		}
+		return refs.Location{Path: filepath.Clean(path), Project: configuredProject(filepath.Dir(path), from.Project)}, nil
+	}
+
+	dir, ok := projectConfig.Projects[ref.Project]
+	if !ok {
+		return refs.Location{}, fmt.Errorf("unknown project '%s'. Add it to \"projects\" in %s", ref.Project, config.GetProjectConfigPath())
+	}
+	dir, err := filepath.Abs(projectConfig.Path(dir))
+	if err != nil {
+		return refs.Location{}, fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	target, err := config.ReadProjectConfig(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return refs.Location{}, err
+	}
+	if target != nil {
+		if _, declared := target.Environments[ref.Env]; declared {
+			envConfig, err := target.ForEnv(ref.Env)
+			if err != nil {
+				return refs.Location{}, err
+			}
+			path, err := filepath.Abs(defaultBundlePath(envConfig))
+			if err != nil {
+				return refs.Location{}, fmt.Errorf("failed to resolve bundle path: %w", err)
+			}
+			project := envConfig.ProjectName
+			if project == "" {
+				project = ref.Project
+			}
+			return refs.Location{Path: path, Project: project}, nil
+		}
+	}
+
+	return refs.Location{Path: filepath.Join(dir, ref.Env+".envsnap"), Project: configuredProject(dir, ref.Project)}, nil
+}
+
+// configuredProject returns the project configured in dir, or fallback when the
+// directory has no project config
+func configuredProject(dir, fallback string) string {
+	if projectConfig, err := config.ReadProjectConfig(dir); err == nil && projectConfig.ProjectName != "" {
+		return projectConfig.ProjectName
+	}
+	return fallback
+}
+
+// openReferencedBundle decrypts a referenced bundle with its cached key
+func openReferencedBundle(loc refs.Location) (*dotenv.File, error) {
+	encryptedData, err := readBundleFile(loc.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes, err := loadProjectKeyBytes(loc.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	decryptedData, err := crypto.DecryptWithKey(encryptedData, keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s with the key for '%s': %w", loc.Path, loc.Project, err)
+	}
+
+	env := dotenv.Parse(decryptedData)
+	if env.HasConflicts() {
+		return nil, fmt.Errorf("%s has %w. Fix: `secretsnap edit %s`", loc.Path, dotenv.ErrConflicts, loc.Path)
+	}
+	return env, nil
+}
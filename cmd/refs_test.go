@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/refs"
+)
+
+func TestLocateReference(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	legacy := filepath.Join(root, "legacy")
+	os.MkdirAll(shared, 0755)
+	os.MkdirAll(legacy, 0755)
+	os.WriteFile(filepath.Join(shared, ".secretsnap.json"), []byte(`{
+  "project_name": "shared",
+  "environments": {"prod": {"bundle_path": "envs/production.envsnap", "key_project": "shared-prod"}}
+}`), 0600)
+
+	projectConfig := &config.ProjectConfig{Dir: root, Projects: map[string]string{"shared": "shared", "legacy": "legacy"}}
+
+	tests := []struct {
+		ref     string
+		path    string
+		project string
+	}{
+		{ref: "secretsnap://shared/prod/KEY", path: filepath.Join(shared, "envs", "production.envsnap"), project: "shared-prod"},
+		{ref: "secretsnap://shared/staging/KEY", path: filepath.Join(shared, "staging.envsnap"), project: "shared"},
+		{ref: "secretsnap://legacy/prod/KEY", path: filepath.Join(legacy, "prod.envsnap"), project: "legacy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, _ := refs.Parse(tt.ref)
+			loc, err := locateReference(ref, refs.Location{Path: filepath.Join(root, "secrets.envsnap")}, projectConfig)
+			if err != nil {
+				t.Fatalf("locateReference() error = %v", err)
+			}
+			if loc.Path != tt.path || loc.Project != tt.project {
+				t.Errorf("locateReference() = %+v, want %s for '%s'", loc, tt.path, tt.project)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"secretsnap/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rekeyPass    passFlags
+	rekeyNewPass utils.PassphraseSource
+	rekeyToLocal bool
+)
+
+var rekeyCmd = &cobra.Command{
+	Use:   "rekey [path-to-bundle]",
+	Short: "Re-encrypt a bundle with a new passphrase",
+	Long: `Decrypt a bundle with its current key or passphrase and encrypt it again with
+a new passphrase, or with the cached project key when --to-local is given.
+The new passphrase comes from the --new-pass flags or SECRETSNAP_NEW_PASSPHRASE,
+and is prompted for twice otherwise. The bundle defaults to the project's
+bundle_path and is replaced only once the new one is written.`,
+	Example: `  # Rotate a passphrase kept in a password manager
+  secretsnap rekey --pass-cmd "pass show team/app" --new-pass-cmd "pass show team/app-next"
+
+  # Move a passphrase bundle to the cached project key
+  secretsnap rekey --pass-mode --to-local`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		inputFile := defaultBundlePath(projectConfig)
+		if len(args) == 1 {
+			inputFile = args[0]
+		}
+
+		if rekeyToLocal && (rekeyNewPass.Explicit() || os.Getenv(newPassphraseEnv) != "") {
+			return withExitCode(exitUsage, fmt.Errorf("--to-local does not take a new passphrase"))
+		}
+		if rekeyPass.Stdin && rekeyNewPass.Stdin {
+			return withExitCode(exitUsage, fmt.Errorf("only one passphrase can be read from stdin"))
+		}
+
+		encryptedData, err := readBundleFile(inputFile)
+		if err != nil {
+			return err
+		}
+
+		oldKey, err := resolveBundleKey(projectConfig, rekeyPass.PassphraseSource, rekeyPass.mode)
+		if err != nil {
+			return err
+		}
+		decryptedData, err := oldKey.decrypt(encryptedData)
+		if err != nil {
+			return err
+		}
+
+		newKey := &bundleKey{mode: "local"}
+		if rekeyToLocal {
+			if newKey.key, err = loadProjectKeyBytes(projectConfig.ProjectName); err != nil {
+				return err
+			}
+		} else {
+			newKey.mode = "passphrase"
+			if newKey.workFactor, err = projectConfig.Passphrase.ScryptWorkFactor(); err != nil {
+				return err
+			}
+			if newKey.passphrase, err = utils.GetNewPassphrase(rekeyNewPass, projectConfig.Passphrase); err != nil {
+				return fmt.Errorf("failed to get new passphrase: %w", err)
+			}
+		}
+
+		reencrypted, err := newKey.encrypt(decryptedData)
+		if err != nil {
+			return err
+		}
+
+		// Write next to the bundle and rename, so a failure keeps the old one
+		tempFile := inputFile + ".tmp"
+		if err := os.WriteFile(tempFile, reencrypted, 0644); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if err := os.Rename(tempFile, inputFile); err != nil {
+			os.Remove(tempFile)
+			return fmt.Errorf("failed to replace bundle: %w", err)
+		}
+
+		if jsonOutput() {
+			return writeJSON(rekeyResult{Bundle: inputFile, From: oldKey.mode, To: newKey.mode})
+		}
+		humanf("✅ Re-encrypted %s (%s → %s)\n", inputFile, oldKey.mode, newKey.mode)
+		return nil
+	},
+}
+
+func init() {
+	addPassFlags(rekeyCmd, &rekeyPass)
+	addNewPassFlags(rekeyCmd, &rekeyNewPass, newPassphraseEnv)
+	rekeyCmd.Flags().BoolVarP(&rekeyToLocal, "to-local", "", false, "Encrypt with the cached project key instead of a passphrase")
+}
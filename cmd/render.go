@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/render"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	renderBundle  string
+	renderOutFile string
+	renderPass    passFlags
+	renderForce   bool
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render <template>",
+	Short: "Render a config template with values from a bundle",
+	Long: `Render a Go text/template with the values of a bundle, for apps that read
+YAML, INI or other config files instead of environment variables. Keys are
+available as {{ .KEY }} and through these helpers:
+
+  env "KEY"          the value of KEY, or "" when it is not set
+  required "KEY"     the value of KEY, failing when it is missing or empty
+  default "x" VALUE  VALUE, or "x" when VALUE is empty
+  b64enc VALUE       base64-encode VALUE
+  quote VALUE        double-quote and escape VALUE
+
+The output is written with 0600 permissions, or to stdout without --out.`,
+	Example: `  secretsnap render --bundle secrets.envsnap config.yaml.tmpl -o config.yaml
+  secretsnap render app.ini.tmpl | less`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		bundlePath := renderBundle
+		if bundlePath == "" {
+			bundlePath = defaultBundlePath(projectConfig)
+		}
+
+		encryptedData, err := readBundleFile(bundlePath)
+		if err != nil {
+			return err
+		}
+
+		decryptedData, _, err := decryptBundle(encryptedData, projectConfig, renderPass.PassphraseSource, renderPass.mode)
+		if err != nil {
+			return err
+		}
+
+		env := dotenv.Parse(decryptedData)
+		if err := resolveReferences(env, bundlePath, projectConfig); err != nil {
+			return err
+		}
+
+		output, err := renderTemplateFile(args[0], env)
+		if err != nil {
+			return err
+		}
+
+		if renderOutFile == "" {
+			_, err := os.Stdout.Write(output)
+			return err
+		}
+
+		if _, err := os.Stat(renderOutFile); err == nil && !renderForce {
+			return fmt.Errorf("refusing to overwrite %s. Use `--force`", renderOutFile)
+		}
+
+		if err := writeRendered(renderOutFile, output); err != nil {
+			return err
+		}
+
+		humanf("✅ Rendered %s to %s\n", args[0], renderOutFile)
+		return nil
+	},
+}
+
+func init() {
+	renderCmd.Flags().StringVarP(&renderBundle, "bundle", "b", "", "Bundle file (defaults to the project's bundle_path)")
+	renderCmd.Flags().StringVarP(&renderOutFile, "out", "o", "", "Output file path (defaults to stdout)")
+	addPassFlags(renderCmd, &renderPass)
+	renderCmd.Flags().BoolVarP(&renderForce, "force", "f", false, "Overwrite output file if it exists")
+}
+
+// renderTemplateFile renders the template at path with the values of env
+func renderTemplateFile(path string, env *dotenv.File) ([]byte, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	output, err := render.Render(path, string(text), env.Map())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return output, nil
+}
+
+// writeRendered writes rendered output with 0600 permissions. It goes to a
+// new file that replaces path, so an existing file's looser mode never
+// applies to the secrets.
+func writeRendered(path string, output []byte) error {
+	if err := writeFileAtomic(path, output, 0600); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
@@ -4,94 +4,105 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"secretsnap/internal/config"
-	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/refs"
 	"secretsnap/internal/utils"
 
 	"github.com/spf13/cobra"
 )
 
+// renderDirEnv tells the child where --render wrote its files
+const renderDirEnv = "SECRETSNAP_RENDER_DIR"
+
 var (
-	runPass     string
-	runPassFile string
-	runPassMode bool
+	runPass   passFlags
+	runStrict bool
+	runRender []string
 )
 
 var runCmd = &cobra.Command{
 	Use:   "run [bundle-file] -- [command...]",
 	Short: "Run a command with environment variables from a bundle",
-	Long:  `Decrypt a bundle to temporary environment variables and run a command. The temporary file is securely deleted after execution.`,
-	Args:  cobra.MinimumNArgs(2),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		bundleFile := args[0]
-		commandArgs := args[1:]
+	Long: `Decrypt a bundle to temporary environment variables and run a command. The temporary file is securely deleted after execution.
 
-		// Validate bundle file exists
-		if _, err := os.Stat(bundleFile); os.IsNotExist(err) {
-			return fmt.Errorf("bundle file '%s' does not exist", bundleFile)
-		}
+Without a bundle before "--", the bundle configured for the project (or the
+--env environment) is used, composed with any "layers" from .secretsnap.json.
 
-		encryptedData, err := os.ReadFile(bundleFile)
-		if err != nil {
-			return fmt.Errorf("failed to read bundle file: %v", err)
-		}
+Values of the form secretsnap://<project>/<env>/<KEY> or ref:<path>#<KEY> are
+resolved from the referenced bundle, which is decrypted with its own cached key.
+
+--render template:name renders a config template (see 'secretsnap render') to
+a private temporary directory before the command starts. The command finds
+the file at $SECRETSNAP_RENDER_DIR/name; the directory is removed when the
+command exits.
 
-		if len(encryptedData) == 0 {
-			return fmt.Errorf("bundle file '%s' is empty", bundleFile)
+Bundle lines are passed as written: every KEY=value line verbatim, so quotes
+and inline comments are part of the value, and only the first line of a
+multi-line value is kept. 'secretsnap lint' reports lines run would drop.
+
+The command keeps stdin, so --pass-stdin is not accepted; use --pass-file,
+--pass-fd, --pass-cmd or SECRETSNAP_PASSPHRASE instead.`,
+	Example: `  secretsnap run --render config.yaml.tmpl:config.yaml -- sh -c './server --config "$SECRETSNAP_RENDER_DIR/config.yaml"'`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if runPass.Stdin {
+			return withExitCode(exitUsage, fmt.Errorf("--pass-stdin would take the command's stdin. Use --pass-file, --pass-fd, --pass-cmd or SECRETSNAP_PASSPHRASE"))
 		}
 
 		// Load project config
-		projectConfig, err := config.LoadProjectConfig()
+		projectConfig, err := loadProjectConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+			return err
 		}
 
-		// Determine mode based on flags
-		mode := determineRunMode(runPass, runPassFile, runPassMode)
-
-		var decryptedData []byte
-
-		switch mode {
-		case "passphrase":
-			// Passphrase mode
-			passphrase, err := utils.GetPassphrase(runPass, runPassFile)
-			if err != nil {
-				return fmt.Errorf("failed to get passphrase: %v", err)
-			}
+		// The bundle may be omitted before "--" to use the configured layers
+		var bundleFile string
+		var commandArgs []string
+		switch {
+		case cmd.ArgsLenAtDash() == 0:
+			commandArgs = args
+		case len(args) >= 2:
+			bundleFile, commandArgs = args[0], args[1:]
+		default:
+			return fmt.Errorf("no command given. Usage: secretsnap run [bundle-file] -- <command...>")
+		}
 
-			decryptedData, err = crypto.DecryptWithPassphrase(encryptedData, passphrase)
-			if err != nil {
-				return fmt.Errorf("failed to decrypt: %v", err)
-			}
+		env, layers, mode, err := composeEnv(projectConfig, bundleFile, runPass.PassphraseSource, runPass.mode, true)
+		if err != nil {
+			return err
+		}
+		envVars := runEnviron(layers)
 
-		default:
-			// Local mode (default)
-			projectKey, err := config.GetProjectKey(projectConfig.ProjectName)
-			if err != nil {
-				return fmt.Errorf("no local project key found for '%s'. Fix:\n"+
-					"• On teammate's machine: `secretsnap key export --project %s`\n"+
-					"• Or use passphrase: `--pass`\n"+
-					"• Or use paid pull: `secretsnap login` then `secretsnap pull`",
-					projectConfig.ProjectName, projectConfig.ProjectName)
+		// Warn about keys past their rotation deadline, or refuse with --strict
+		if expired := rotationDue(env, time.Now(), time.Now()); len(expired) > 0 {
+			for _, key := range expired {
+				fmt.Fprintf(stderr, "⚠️  %s expired on %s and should be rotated\n", key.Key, key.Deadline.Format("2006-01-02"))
 			}
-
-			keyBytes, err := crypto.KeyFromBase64(projectKey.KeyB64)
-			if err != nil {
-				return fmt.Errorf("failed to decode project key: %v", err)
+			if runStrict {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("refusing to inject %d expired key(s) with --strict. Rotate them with `secretsnap set`", len(expired))
 			}
+		}
 
-			decryptedData, err = crypto.DecryptWithKey(encryptedData, keyBytes)
-			if err != nil {
-				return fmt.Errorf("failed to decrypt: %v", err)
+		// Render config templates for the lifetime of the command
+		var renderDir string
+		if len(runRender) > 0 {
+			if renderDir, err = os.MkdirTemp("", "secretsnap-render-"); err != nil {
+				return fmt.Errorf("failed to create render directory: %w", err)
 			}
+			defer os.RemoveAll(renderDir)
 		}
-
-		// Parse environment variables from decrypted data
-		envVars, err := parseEnvFile(decryptedData)
-		if err != nil {
-			return fmt.Errorf("failed to parse environment variables: %v", err)
+		for _, spec := range runRender {
+			if err := renderForRun(spec, renderDir, env); err != nil {
+				return err
+			}
 		}
 
 		// Create command
@@ -102,23 +113,49 @@ var runCmd = &cobra.Command{
 
 		// Set environment variables
 		command.Env = append(os.Environ(), envVars...)
+		if renderDir != "" {
+			command.Env = append(command.Env, renderDirEnv+"="+renderDir)
+		}
+
+		// Run command. Ctrl-C already reaches the child through the
+		// terminal, so interrupts are only caught to wait for it to exit and
+		// clean up rendered files; other signals are forwarded.
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
 
-		// Run command
-		if err := command.Run(); err != nil {
-			return fmt.Errorf("command failed: %v", err)
+		if err := command.Start(); err != nil {
+			return withExitCode(127, fmt.Errorf("command failed: %w", err))
+		}
+		go func() {
+			for sig := range signals {
+				if sig != os.Interrupt {
+					command.Process.Signal(sig)
+				}
+			}
+		}()
+		if err := command.Wait(); err != nil {
+			// Exit with the child's own status; 128+N if it was killed
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+					return &childExitError{code: 128 + int(status.Signal())}
+				}
+				return &childExitError{code: exitErr.ExitCode()}
+			}
+			return fmt.Errorf("command failed: %w", err)
 		}
 
 		// Track usage and show upsell for free users
 		if mode == "local" || mode == "passphrase" {
 			if err := config.IncrementFreeRun(); err != nil {
 				// Don't fail the command if upsell tracking fails
-				fmt.Fprintf(os.Stderr, "Warning: failed to track usage: %v\n", err)
+				fmt.Fprintf(stderr, "Warning: failed to track usage: %v\n", err)
 			}
-			
+
 			// Show contextual upsell
 			if err := utils.ShowContextualUpsell("run"); err != nil {
 				// Don't fail the command if upsell fails
-				fmt.Fprintf(os.Stderr, "Warning: failed to show upsell: %v\n", err)
+				fmt.Fprintf(stderr, "Warning: failed to show upsell: %v\n", err)
 			}
 		}
 
@@ -127,20 +164,85 @@ var runCmd = &cobra.Command{
 }
 
 func init() {
-	runCmd.Flags().StringVarP(&runPass, "pass", "p", "", "Passphrase (prompted if not provided)")
-	runCmd.Flags().StringVarP(&runPassFile, "pass-file", "", "", "Read passphrase from file")
-	runCmd.Flags().BoolVarP(&runPassMode, "pass-mode", "", false, "Use passphrase mode (prompt for passphrase)")
+	addPassFlags(runCmd, &runPass)
+	runCmd.Flags().StringArrayVarP(&runRender, "render", "", nil, "Render a template to $SECRETSNAP_RENDER_DIR/name while the command runs (template:name)")
+	runCmd.Flags().BoolVarP(&runStrict, "strict", "", false, "Fail instead of warning when a key is past its rotation deadline")
 }
 
-// determineRunMode determines the decryption mode based on flags
-func determineRunMode(pass, passFile string, passMode bool) string {
-	if pass != "" || passFile != "" || passMode {
-		return "passphrase"
+// renderForRun renders a template:name spec for the child command into dir
+func renderForRun(spec, dir string, env *dotenv.File) error {
+	template, name, ok := strings.Cut(spec, ":")
+	if !ok || template == "" || name == "" {
+		return fmt.Errorf("invalid --render '%s' (use template:name)", spec)
+	}
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("invalid --render '%s': %s must be a relative path inside %s", spec, name, renderDirEnv)
+	}
+
+	output, err := renderTemplateFile(template, env)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create render directory: %w", err)
 	}
-	return "local"
+	if err := os.WriteFile(path, output, 0600); err != nil {
+		return fmt.Errorf("failed to write rendered file: %w", err)
+	}
+
+	return nil
 }
 
+// runEnviron returns the variables passed to the command. Bundle lines are
+// read as run always has, by parseEnvFile, so existing bundles keep their
+// values: quotes, escapes and inline comments reach the command as written.
+// References, file layers and the process layer use the parsed values.
+func runEnviron(layers []*layer) []string {
+	var names []string
+	values := make(map[string]string)
+	set := func(name, value string) {
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = value
+	}
+
+	for _, l := range layers {
+		if l.Skipped != "" {
+			continue
+		}
+		switch l.Kind {
+		case "bundle":
+			entries, _ := parseEnvFile(l.raw)
+			for _, entry := range entries {
+				name, value, _ := strings.Cut(entry, "=")
+				if _, ok := refs.Parse(value); ok {
+					value, _ = l.File.Get(name)
+				}
+				set(name, value)
+			}
+		case "file":
+			parsed := l.File.Map()
+			for _, key := range l.File.Keys() {
+				set(key, parsed[key])
+			}
+		case "process":
+			for _, name := range names {
+				if value, ok := os.LookupEnv(name); ok {
+					set(name, value)
+				}
+			}
+		}
+	}
 
+	environ := make([]string, 0, len(names))
+	for _, name := range names {
+		environ = append(environ, name+"="+values[name])
+	}
+	return environ
+}
 
 // parseEnvFile parses environment variables from a .env file format
 func parseEnvFile(data []byte) ([]string, error) {
@@ -149,7 +251,7 @@ func parseEnvFile(data []byte) ([]string, error) {
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		
+
 		// Skip empty lines and comments
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
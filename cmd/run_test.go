@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"secretsnap/internal/dotenv"
+)
+
+func TestRunEnviron(t *testing.T) {
+	raw := []byte("A=plain\nB=\"quoted value\"\nC=x # comment\nexport D=1\nREF=ref:other.envsnap#KEY\n")
+	bundle := &layer{Kind: "bundle", raw: raw, File: dotenv.Parse(raw)}
+	bundle.File.Set("REF", "resolved")
+	local := &layer{Kind: "file", File: dotenv.Parse([]byte("A=\"from file\"\n"))}
+
+	got := strings.Join(runEnviron([]*layer{bundle, local}), "\n")
+	want := strings.Join([]string{
+		"A=from file",
+		`B="quoted value"`,
+		"C=x # comment",
+		"export D=1",
+		"REF=resolved",
+	}, "\n")
+	if got != want {
+		t.Errorf("runEnviron() =\n%s\nwant\n%s", got, want)
+	}
+}
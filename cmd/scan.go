@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/scan"
+
+	"github.com/spf13/cobra"
+)
+
+// scanMaxFileSize skips large files such as build artifacts
+const scanMaxFileSize = 10 << 20
+
+var (
+	scanBundles []string
+	scanPass    passFlags
+	scanHistory bool
+	scanFormat  string
+)
+
+// scanFinding is one leaked value. It never contains the value itself.
+type scanFinding struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Key      string `json:"key"`
+	Encoding string `json:"encoding"`
+	Commit   string `json:"commit,omitempty"`
+}
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [path]",
+	Short: "Search files and git history for leaked bundle values",
+	Long: `Decrypt bundles in memory and search for their values in the files under path
+(the current directory by default). Values are also found when they are
+base64-encoded, URL-encoded or JSON-escaped. Findings report the file, line
+and key, never the value, and the command exits non-zero when there are any.
+
+Inside a git repository, tracked and untracked files are scanned and ignored
+files such as .env are skipped. Elsewhere every file is scanned except
+dotenv files and bundles. --history also scans every commit with git log -p.
+
+Values shorter than 8 characters are not searched for.`,
+	Example: `  secretsnap scan
+  secretsnap scan --history --bundle prod.envsnap --bundle staging.envsnap`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if scanFormat != "text" && scanFormat != "json" {
+			return fmt.Errorf("format must be 'text' or 'json', got '%s'", scanFormat)
+		}
+
+		root := "."
+		if len(args) == 1 {
+			root = args[0]
+		}
+
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		bundles := scanBundles
+		if len(bundles) == 0 {
+			bundles = []string{defaultBundlePath(projectConfig)}
+		}
+
+		// Merge the values of all bundles; the plaintext is dropped once
+		// the matcher has hashed it
+		values := make(map[string]string)
+		for _, path := range bundles {
+			encryptedData, err := readBundleFile(path)
+			if err != nil {
+				return err
+			}
+			decryptedData, _, err := decryptBundle(encryptedData, projectConfig, scanPass.PassphraseSource, scanPass.mode)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			for key, value := range dotenv.Parse(decryptedData).Map() {
+				values[path+"#"+key] = value
+			}
+		}
+
+		matcher, err := scan.NewMatcher(values)
+		if err != nil {
+			return err
+		}
+		clear(values)
+		if matcher.Empty() {
+			fmt.Fprintf(stderr, "⚠️  No values of %d+ characters to search for\n", scan.MinValueLength)
+			return nil
+		}
+
+		findings, err := scanFiles(root, matcher)
+		if err != nil {
+			return err
+		}
+
+		if scanHistory {
+			historyFindings, err := scanGitHistory(root, matcher)
+			if err != nil {
+				return err
+			}
+			findings = append(findings, historyFindings...)
+		}
+
+		// Report keys without the bundle prefix when only one was scanned
+		for i := range findings {
+			if len(bundles) == 1 {
+				findings[i].Key = strings.TrimPrefix(findings[i].Key, bundles[0]+"#")
+			}
+		}
+
+		if scanFormat == "json" {
+			if findings == nil {
+				findings = []scanFinding{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(findings); err != nil {
+				return fmt.Errorf("failed to encode findings: %w", err)
+			}
+		} else {
+			printScanFindings(findings)
+		}
+
+		if len(findings) > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("found %d leaked value(s)", len(findings))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	scanCmd.Flags().StringArrayVarP(&scanBundles, "bundle", "b", nil, "Bundle whose values to search for (repeatable, defaults to the project's bundle_path)")
+	addPassFlags(scanCmd, &scanPass)
+	scanCmd.Flags().BoolVarP(&scanHistory, "history", "", false, "Also scan git history (git log -p)")
+	scanCmd.Flags().StringVarP(&scanFormat, "format", "", "text", "Output format (text|json)")
+}
+
+// scanFiles searches the files under root
+func scanFiles(root string, matcher *scan.Matcher) ([]scanFinding, error) {
+	paths, err := scanPaths(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []scanFinding
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".envsnap") {
+			continue
+		}
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Size() > scanMaxFileSize {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || scan.IsBinary(content) {
+			continue
+		}
+		for _, match := range matcher.Find(content) {
+			findings = append(findings, scanFinding{Path: path, Line: match.Line, Key: match.Key, Encoding: match.Encoding})
+		}
+	}
+
+	return findings, nil
+}
+
+// scanPaths lists the files to scan: the files git knows about when root
+// is inside a repository, and a plain walk otherwise
+func scanPaths(root string) ([]string, error) {
+	if _, err := gitOutput("-C", root, "rev-parse", "--git-dir"); err == nil {
+		out, err := exec.Command("git", "-C", root, "ls-files", "-z", "--cached", "--others", "--exclude-standard").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		var paths []string
+		for _, path := range strings.Split(string(out), "\x00") {
+			if path != "" {
+				paths = append(paths, filepath.Join(root, path))
+			}
+		}
+		return paths, nil
+	}
+
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && !scan.IsDotenvName(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	return paths, nil
+}
+
+// scanGitHistory searches the lines added by every commit. Line numbers
+// refer to the file as of that commit.
+func scanGitHistory(root string, matcher *scan.Matcher) ([]scanFinding, error) {
+	command := exec.Command("git", "-C", root, "log", "-p", "--all", "--no-color", "--no-ext-diff", "--no-textconv", "--format=commit %H")
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git history: %w", err)
+	}
+	if err := command.Start(); err != nil {
+		return nil, fmt.Errorf("failed to read git history: %w", err)
+	}
+
+	var findings []scanFinding
+	var commit, path string
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), scanMaxFileSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "commit "):
+			commit = strings.TrimPrefix(line, "commit ")
+		case strings.HasPrefix(line, "+++ "):
+			path = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+		case strings.HasPrefix(line, "@@ "):
+			lineNumber = hunkStart(line)
+		case strings.HasPrefix(line, "+"):
+			if !strings.HasSuffix(path, ".envsnap") {
+				for _, match := range matcher.FindLine([]byte(line[1:])) {
+					findings = append(findings, scanFinding{Path: path, Line: lineNumber, Key: match.Key, Encoding: match.Encoding, Commit: commit})
+				}
+			}
+			lineNumber++
+		case strings.HasPrefix(line, " "):
+			lineNumber++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read git history: %w", err)
+	}
+	if err := command.Wait(); err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	return findings, nil
+}
+
+// hunkStart returns the first new-file line of a "@@ -a,b +c,d @@" header
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+// printScanFindings prints one line per finding
+func printScanFindings(findings []scanFinding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(stdout, "✅ No leaked values found")
+		return
+	}
+
+	for _, finding := range findings {
+		location := fmt.Sprintf("%s:%d", finding.Path, finding.Line)
+		if finding.Commit != "" {
+			location = finding.Commit[:12] + " " + location
+		}
+		encoding := ""
+		if finding.Encoding != "plain" {
+			encoding = " (" + finding.Encoding + "-encoded)"
+		}
+		fmt.Fprintf(stdout, "🚨 %s: value of %s%s\n", location, finding.Key, encoding)
+	}
+	fmt.Fprintf(stdout, "\n⚠️  Rotate the leaked keys: removing them from the files is not enough\n")
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/secretgen"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	setBundle   string
+	setPass     passFlags
+	setGenerate string
+	setPrint    bool
+
+	setOwner       string
+	setDescription string
+	setRotateEvery string
+	setExpires     string
+)
+
+var setCmd = &cobra.Command{
+	Use:   "set KEY [VALUE]",
+	Short: "Set a value inside an encrypted bundle",
+	Long: `Set a key inside an encrypted bundle without writing a plaintext file. The
+value comes from the argument, from stdin when no value is given, or from a
+built-in generator:
+
+  password:N  alnum:N  hex:N  base64:N  uuid  jwt-hs256  rsa-2048  ed25519
+
+Generators use crypto/rand and the value is never shown unless --print is set.
+Keypair generators store the private key in KEY and the public key in
+KEY_PUBLIC. The bundle defaults to the project's bundle_path and is created
+if it does not exist.
+
+Values that need quoting (spaces, quotes, '#', '$', several lines, such as
+rsa-2048 and ed25519 keys) are written double-quoted. 'secretsnap run' passes
+bundle lines as written, quotes included, so read those through unbundle or
+render instead.
+
+Each key also carries metadata that is encrypted with the bundle: created and
+rotated timestamps are kept up to date automatically, and --owner,
+--description, --rotate-every and --expires record the rest. See
+'secretsnap stale' for keys that are overdue for rotation.`,
+	Example: `  secretsnap set DB_PASSWORD --generate password:32
+  secretsnap set JWT_SIGNING_KEY --generate ed25519
+  secretsnap set STRIPE_KEY --owner payments --rotate-every 90d < stripe.key
+  echo -n "$TOKEN" | secretsnap set API_TOKEN`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		if !dotenv.ValidKey(key) {
+			return fmt.Errorf("'%s' is not a valid variable name", key)
+		}
+
+		if len(args) == 2 && setGenerate != "" {
+			return fmt.Errorf("pass either a value or --generate, not both")
+		}
+
+		if setRotateEvery != "" {
+			if _, err := dotenv.ParseInterval(setRotateEvery); err != nil {
+				return err
+			}
+		}
+
+		var expiresAt *time.Time
+		if setExpires != "" {
+			t, err := dotenv.ParseDate(setExpires)
+			if err != nil {
+				return err
+			}
+			expiresAt = &t
+		}
+
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		bundlePath := setBundle
+		if bundlePath == "" {
+			bundlePath = defaultBundlePath(projectConfig)
+		}
+
+		values := map[string]string{}
+		switch {
+		case setGenerate != "":
+			secret, err := secretgen.Generate(setGenerate)
+			if err != nil {
+				return err
+			}
+			values[key] = secret.Value
+			if secret.Public != "" {
+				values[key+"_PUBLIC"] = secret.Public
+			}
+		case len(args) == 2:
+			values[key] = args[1]
+		default:
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read value from stdin: %w", err)
+			}
+			values[key] = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		}
+
+		bundleKey, err := resolveBundleKey(projectConfig, setPass.PassphraseSource, setPass.mode)
+		if err != nil {
+			return err
+		}
+
+		// Start from the existing bundle, or an empty one
+		env := &dotenv.File{}
+		if _, err := os.Stat(bundlePath); err == nil {
+			encryptedData, err := readBundleFile(bundlePath)
+			if err != nil {
+				return err
+			}
+			decryptedData, err := bundleKey.decryptResolved(encryptedData)
+			if err != nil {
+				return err
+			}
+			env = dotenv.Parse(decryptedData)
+		}
+
+		previous, existed := env.Get(key)
+		for _, name := range []string{key, key + "_PUBLIC"} {
+			if value, ok := values[name]; ok {
+				env.Set(name, value)
+			}
+		}
+
+		// Keep the key's metadata current
+		now := time.Now().UTC().Truncate(time.Second)
+		meta, ok := env.Meta(key)
+		if !ok {
+			meta = &dotenv.Meta{Key: key, CreatedAt: &now}
+		}
+		if !existed || previous != values[key] {
+			meta.RotatedAt = &now
+		}
+		if setOwner != "" {
+			meta.Owner = setOwner
+		}
+		if setDescription != "" {
+			meta.Description = setDescription
+		}
+		if setRotateEvery != "" {
+			meta.RotateEvery = setRotateEvery
+		}
+		if expiresAt != nil {
+			meta.ExpiresAt = expiresAt
+		}
+		if err := env.SetMeta(meta); err != nil {
+			return err
+		}
+
+		encryptedData, err := bundleKey.encrypt(env.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if err := writeFileAtomic(bundlePath, encryptedData, 0600); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+
+		if setPrint {
+			fmt.Fprintln(stdout, values[key])
+			if public, ok := values[key+"_PUBLIC"]; ok {
+				fmt.Fprintln(stdout, public)
+			}
+		}
+
+		if quiet {
+			return nil
+		}
+		if _, ok := values[key+"_PUBLIC"]; ok {
+			fmt.Fprintf(stderr, "✅ Set %s and %s_PUBLIC in %s\n", key, key, bundlePath)
+		} else {
+			fmt.Fprintf(stderr, "✅ Set %s in %s\n", key, bundlePath)
+		}
+		if setGenerate != "" && !setPrint {
+			fmt.Fprintf(stderr, "🎲 Generated with %s (value hidden, use --print to show it)\n", setGenerate)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	setCmd.Flags().StringVarP(&setBundle, "bundle", "b", "", "Bundle file (defaults to the project's bundle_path)")
+	addPassFlags(setCmd, &setPass)
+	setCmd.Flags().StringVarP(&setGenerate, "generate", "g", "", "Generate the value (e.g. password:32, hex:32, uuid, ed25519)")
+	setCmd.Flags().BoolVarP(&setPrint, "print", "", false, "Print the value to stdout")
+	setCmd.Flags().StringVarP(&setOwner, "owner", "", "", "Owner of the key (team or person)")
+	setCmd.Flags().StringVarP(&setDescription, "description", "", "", "What the key is for")
+	setCmd.Flags().StringVarP(&setRotateEvery, "rotate-every", "", "", "Rotation interval (e.g. 90d, 12w)")
+	setCmd.Flags().StringVarP(&setExpires, "expires", "", "", "Expiry date (YYYY-MM-DD or RFC 3339)")
+}
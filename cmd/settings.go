@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"secretsnap/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var settingsCmd = &cobra.Command{
+	Use:   "settings [name [on|off]]",
+	Short: "Show or change user settings",
+	Long: `Show or change settings kept in the secretsnap config directory.
+
+  quiet   only print command data, as with --quiet
+  plain   no emoji in output, as with --plain
+  upsell  show upgrade banners on free plans`,
+	Example: `  secretsnap settings
+  secretsnap settings upsell off`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 2 {
+			if err := settings.Set(args[0], args[1]); err != nil {
+				return withExitCode(exitUsage, err)
+			}
+			if err := config.SaveSettings(settings); err != nil {
+				return err
+			}
+			humanf("✅ Set %s to %s in %s\n", args[0], onOff(settings, args[0]), config.GetSettingsPath())
+			return nil
+		}
+
+		names := config.SettingNames
+		if len(args) == 1 {
+			names = []string{args[0]}
+		}
+		for _, name := range names {
+			if _, err := settings.Get(name); err != nil {
+				return withExitCode(exitUsage, err)
+			}
+			fmt.Fprintf(stdout, "%-7s %s\n", name, onOff(settings, name))
+		}
+		return nil
+	},
+}
+
+// onOff formats a known setting
+func onOff(settings *config.Settings, name string) string {
+	if value, _ := settings.Get(name); value {
+		return "on"
+	}
+	return "off"
+}
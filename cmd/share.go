@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"secretsnap/internal/api"
 	"secretsnap/internal/config"
@@ -23,18 +22,18 @@ var shareCmd = &cobra.Command{
 	Long:  `Share a project with another user by email address.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load project config and token
-		projectConfig, err := config.LoadProjectConfig()
+		projectConfig, err := readProjectConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+			return err
 		}
 
 		token, err := config.LoadToken()
 		if err != nil {
-			return fmt.Errorf("failed to load token: %v", err)
+			return fmt.Errorf("failed to load token: %w", err)
 		}
 
 		if token == "" {
-			return fmt.Errorf("not logged in. Run 'secretsnap login --license <KEY>' first")
+			return fmt.Errorf("%w. Run 'secretsnap login --license <KEY>' first", config.ErrNotLoggedIn)
 		}
 
 		// Use project from config if not specified
@@ -65,17 +64,21 @@ var shareCmd = &cobra.Command{
 		// Share project
 		err = client.Share(shareProject, shareUser, shareRole)
 		if err != nil {
-			return fmt.Errorf("failed to share project: %v", err)
+			return fmt.Errorf("failed to share project: %w", err)
 		}
 
-		fmt.Printf("✅ Invited %s\n", shareUser)
-		fmt.Printf("🔑 Role: %s\n", shareRole)
-		fmt.Printf("📦 Project: %s\n", projectConfig.ProjectName)
+		humanf("✅ Invited %s\n", shareUser)
+		humanf("🔑 Role: %s\n", shareRole)
+		humanf("📦 Project: %s\n", projectConfig.ProjectName)
 
 		// Show feature-specific upsell for team sharing
 		if err := utils.ShowFeatureUpsell("team"); err != nil {
 			// Don't fail the command if upsell fails
-			fmt.Fprintf(os.Stderr, "Warning: failed to show upsell: %v\n", err)
+			fmt.Fprintf(stderr, "Warning: failed to show upsell: %v\n", err)
+		}
+
+		if jsonOutput() {
+			return writeJSON(shareResult{Project: shareProject, User: shareUser, Role: shareRole})
 		}
 
 		return nil
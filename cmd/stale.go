@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"secretsnap/internal/dotenv"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	stalePass   passFlags
+	staleWithin string
+	staleFormat string
+)
+
+// staleKey is one row of the stale report
+type staleKey struct {
+	Key      string    `json:"key"`
+	Owner    string    `json:"owner,omitempty"`
+	Deadline time.Time `json:"deadline"`
+	Overdue  bool      `json:"overdue"`
+}
+
+var staleCmd = &cobra.Command{
+	Use:   "stale [path-to-bundle]",
+	Short: "List keys that are overdue for rotation",
+	Long: `List keys whose expiry date or rotation interval (set with 'secretsnap set
+--expires/--rotate-every') has passed. Use --within to also list keys that fall
+due soon. Exits non-zero when any key is overdue. The bundle defaults to the
+project's bundle_path.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if staleFormat != "text" && staleFormat != "json" {
+			return fmt.Errorf("format must be 'text' or 'json', got '%s'", staleFormat)
+		}
+
+		var within time.Duration
+		if staleWithin != "" {
+			d, err := dotenv.ParseInterval(staleWithin)
+			if err != nil {
+				return err
+			}
+			within = d
+		}
+
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		inputFile := defaultBundlePath(projectConfig)
+		if len(args) == 1 {
+			inputFile = args[0]
+		}
+
+		encryptedData, err := readBundleFile(inputFile)
+		if err != nil {
+			return err
+		}
+
+		decryptedData, _, err := decryptBundle(encryptedData, projectConfig, stalePass.PassphraseSource, stalePass.mode)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		keys := rotationDue(dotenv.Parse(decryptedData), now.Add(within), now)
+		overdue := 0
+		for _, key := range keys {
+			if key.Overdue {
+				overdue++
+			}
+		}
+
+		if staleFormat == "json" {
+			if keys == nil {
+				keys = []staleKey{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(keys); err != nil {
+				return fmt.Errorf("failed to encode report: %w", err)
+			}
+		} else if len(keys) == 0 {
+			fmt.Fprintf(stdout, "✅ No keys are due for rotation in %s\n", inputFile)
+		} else {
+			w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tOWNER\tDUE\tSTATUS")
+			for _, key := range keys {
+				status := "due soon"
+				if key.Overdue {
+					status = fmt.Sprintf("overdue by %s", formatDays(now.Sub(key.Deadline)))
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", key.Key, key.Owner, key.Deadline.Format("2006-01-02"), status)
+			}
+			w.Flush()
+		}
+
+		if overdue > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d key(s) overdue for rotation", overdue)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addPassFlags(staleCmd, &stalePass)
+	staleCmd.Flags().StringVarP(&staleWithin, "within", "", "", "Also list keys due within this interval (e.g. 14d)")
+	staleCmd.Flags().StringVarP(&staleFormat, "format", "", "text", "Output format (text|json)")
+}
+
+// rotationDue returns the keys in env whose deadline is before cutoff,
+// oldest deadline first. Keys past now are marked overdue.
+func rotationDue(env *dotenv.File, cutoff, now time.Time) []staleKey {
+	present := env.Map()
+	var keys []staleKey
+
+	for key, meta := range env.AllMeta() {
+		if _, ok := present[key]; !ok {
+			continue
+		}
+		deadline, ok := meta.Deadline()
+		if !ok || !deadline.Before(cutoff) {
+			continue
+		}
+		keys = append(keys, staleKey{
+			Key:      key,
+			Owner:    meta.Owner,
+			Deadline: deadline,
+			Overdue:  now.After(deadline),
+		})
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Deadline.Before(keys[j].Deadline) })
+	return keys
+}
+
+// formatDays renders a duration in whole days, or hours when under a day
+func formatDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"secretsnap/internal/api"
+	"secretsnap/internal/config"
+	"secretsnap/internal/crypto"
+	"secretsnap/internal/dotenv"
+	"secretsnap/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// Sync states reported by status
+const (
+	statusInSync      = "in-sync"
+	statusLocalAhead  = "local-ahead"
+	statusBundleAhead = "bundle-ahead"
+	statusDiverged    = "diverged"
+)
+
+var (
+	statusFile   string
+	statusPass   passFlags
+	statusFormat string
+)
+
+// statusReport is the machine-readable output of `status --format json`
+type statusReport struct {
+	File    string          `json:"file"`
+	Bundle  string          `json:"bundle"`
+	State   string          `json:"state"`
+	Guessed bool            `json:"guessed,omitempty"` // no sync record, decided by modification times
+	Changes []dotenv.Change `json:"changes"`
+
+	RemoteVersion int `json:"remote_version,omitempty"`
+	SyncedVersion int `json:"synced_version,omitempty"`
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether .env and the bundle have drifted apart",
+	Long: `Compare the plaintext .env with the decrypted bundle and report one of:
+
+  in-sync       both have the same keys and values
+  local-ahead   .env changed since the last bundle/unbundle; re-bundle it
+  bundle-ahead  the bundle changed since then; unbundle it
+  diverged      both changed
+
+The differing key names are listed, never their values. bundle and unbundle
+record a salted fingerprint of each sync in .secretsnap/state.json; without
+one, the newer file is assumed to be ahead. In cloud mode the latest remote
+version is compared with the last one pushed or pulled.
+
+The command exits non-zero unless everything is in sync.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if workspaceAll {
+			return runWorkspace(cmd, args, &statusPass)
+		}
+
+		if statusFormat != "text" && statusFormat != "json" {
+			return fmt.Errorf("format must be 'text' or 'json', got '%s'", statusFormat)
+		}
+
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		state, err := config.LoadSyncState()
+		if err != nil {
+			return err
+		}
+
+		report := statusReport{File: statusFile, Bundle: defaultBundlePath(projectConfig), Changes: []dotenv.Change{}}
+
+		plaintext, err := os.ReadFile(report.File)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", report.File, err)
+		}
+		local := dotenv.Parse(plaintext)
+
+		// Cloud projects may not keep a local bundle at all
+		report.State = statusInSync
+		if _, err := os.Stat(report.Bundle); err == nil || projectConfig.Mode != "cloud" {
+			encryptedData, err := readBundleFile(report.Bundle)
+			if err != nil {
+				return err
+			}
+			decryptedData, _, err := decryptBundle(encryptedData, projectConfig, statusPass.PassphraseSource, statusPass.mode)
+			if err != nil {
+				return err
+			}
+
+			report.State, report.Guessed, report.Changes = compareSync(state, report.File, report.Bundle, local, dotenv.Parse(decryptedData))
+		}
+
+		// A newer remote version means the bundle moved on without us
+		if projectConfig.Mode == "cloud" {
+			report.RemoteVersion, report.SyncedVersion, err = remoteVersions(projectConfig, state)
+			if err != nil {
+				fmt.Fprintf(stderr, "⚠️  Could not check the remote version: %v\n", err)
+			}
+			if report.RemoteVersion > report.SyncedVersion {
+				switch report.State {
+				case statusInSync:
+					report.State = statusBundleAhead
+				case statusLocalAhead:
+					report.State = statusDiverged
+				}
+			}
+		}
+
+		if statusFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return fmt.Errorf("failed to encode report: %w", err)
+			}
+		} else {
+			printStatusReport(report)
+		}
+
+		if report.State != statusInSync {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s and %s are not in sync", report.File, report.Bundle)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(&statusFile, "file", "", ".env", "Plaintext file to compare")
+	addPassFlags(statusCmd, &statusPass)
+	statusCmd.Flags().StringVarP(&statusFormat, "format", "", "text", "Output format (text|json)")
+	addWorkspaceFlags(statusCmd)
+}
+
+// compareSync decides which side changed since the last recorded sync and
+// lists the differing keys without their values
+func compareSync(state *config.SyncState, file, bundle string, local, bundled *dotenv.File) (string, bool, []dotenv.Change) {
+	changes := []dotenv.Change{}
+	for _, change := range dotenv.Diff(bundled, local) {
+		changes = append(changes, dotenv.Change{Key: change.Key, Kind: change.Kind})
+	}
+
+	localFingerprint := envFingerprint(state, local)
+	bundleFingerprint := envFingerprint(state, bundled)
+	last, recorded := state.Bundles[syncKey(bundle)]
+
+	switch {
+	case localFingerprint == bundleFingerprint:
+		return statusInSync, false, changes
+	case recorded && localFingerprint == last.Fingerprint:
+		return statusBundleAhead, false, changes
+	case recorded && bundleFingerprint == last.Fingerprint:
+		return statusLocalAhead, false, changes
+	case recorded:
+		return statusDiverged, false, changes
+	case newerThan(file, bundle):
+		return statusLocalAhead, true, changes
+	default:
+		return statusBundleAhead, true, changes
+	}
+}
+
+// envFingerprint is a salted hash of a file's final keys and values.
+// Comments, ordering and quoting do not change it.
+func envFingerprint(state *config.SyncState, env *dotenv.File) string {
+	values := env.Map()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(dotenv.FormatLine(key, values[key]))
+		b.WriteString("\n")
+	}
+
+	salt, _ := hex.DecodeString(state.Salt)
+	return crypto.NewValueHasherWithKey(salt).Hash(b.String())
+}
+
+// recordSync remembers that bundlePath and its plaintext now match. It is
+// best effort: failing to record only makes status guess later.
+func recordSync(bundlePath string, plaintext []byte) {
+	state, err := config.LoadSyncState()
+	if err != nil {
+		return
+	}
+
+	if state.Salt == "" {
+		salt, err := crypto.GenerateDataKey()
+		if err != nil {
+			return
+		}
+		state.Salt = hex.EncodeToString(salt)
+	}
+
+	state.Bundles[syncKey(bundlePath)] = config.BundleSync{
+		Fingerprint: envFingerprint(state, dotenv.Parse(plaintext)),
+		SyncedAt:    time.Now().UTC(),
+	}
+	config.SaveSyncState(state)
+}
+
+// syncKey names a bundle in the sync state by its path relative to the
+// project directory, so status works from any subdirectory
+func syncKey(bundlePath string) string {
+	if rel, err := filepath.Rel(config.ProjectDir(), bundlePath); err == nil && !filepath.IsAbs(bundlePath) {
+		return rel
+	}
+	return filepath.Clean(bundlePath)
+}
+
+// recordRemoteVersion remembers the cloud version last pushed or pulled
+func recordRemoteVersion(projectID string, version int) {
+	state, err := config.LoadSyncState()
+	if err != nil {
+		return
+	}
+	state.Remote[projectID] = version
+	config.SaveSyncState(state)
+}
+
+// remoteVersions returns the latest cloud version and the last one synced.
+// The version comes from the pull endpoint, the only one the API has for
+// it; the bundle itself is not downloaded.
+func remoteVersions(projectConfig *config.ProjectConfig, state *config.SyncState) (int, int, error) {
+	token, err := config.LoadToken()
+	if err != nil {
+		return 0, 0, err
+	}
+	if token == "" {
+		return 0, 0, config.ErrNotLoggedIn
+	}
+
+	resp, err := api.NewClient(utils.GetAPIURL(), token).BundlePull(projectConfig.ProjectID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return resp.Version, state.Remote[projectConfig.ProjectID], nil
+}
+
+// newerThan reports whether a was modified after b
+func newerThan(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && infoA.ModTime().After(infoB.ModTime())
+}
+
+// printStatusReport prints the sync state, the differing keys and a hint
+func printStatusReport(report statusReport) {
+	icons := map[string]string{
+		statusInSync:      "✅",
+		statusLocalAhead:  "📝",
+		statusBundleAhead: "📦",
+		statusDiverged:    "⚠️ ",
+	}
+
+	fmt.Fprintf(stdout, "%s %s vs %s: %s\n", icons[report.State], report.File, report.Bundle, report.State)
+	if report.Guessed {
+		fmt.Fprintf(stdout, "   (no sync recorded yet, guessed from modification times)\n")
+	}
+
+	symbols := map[string]string{dotenv.Added: "+", dotenv.Removed: "-", dotenv.Changed: "~"}
+	for _, change := range report.Changes {
+		fmt.Fprintf(stdout, "   %s %s\n", symbols[change.Kind], change.Key)
+	}
+
+	switch report.State {
+	case statusLocalAhead:
+		fmt.Fprintf(stdout, "💡 Update the bundle: secretsnap bundle %s --out %s --force\n", report.File, report.Bundle)
+	case statusBundleAhead:
+		fmt.Fprintf(stdout, "💡 Update %s: secretsnap unbundle %s --out %s --force\n", report.File, report.Bundle, report.File)
+	case statusDiverged:
+		fmt.Fprintf(stdout, "💡 Both changed. Review with `secretsnap diff %s %s --show-values` before overwriting either\n", report.Bundle, report.File)
+	}
+
+	if report.RemoteVersion > 0 {
+		if report.RemoteVersion > report.SyncedVersion {
+			fmt.Fprintf(stdout, "☁️  Remote is at v%d, you last synced v%d. Run `secretsnap pull`\n", report.RemoteVersion, report.SyncedVersion)
+		} else {
+			fmt.Fprintf(stdout, "☁️  Remote is at v%d, up to date\n", report.RemoteVersion)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/dotenv"
+)
+
+func TestCompareSync(t *testing.T) {
+	synced := dotenv.Parse([]byte("A=1\nB=2\n"))
+	edited := dotenv.Parse([]byte("A=1\nB=2\nC=3\n"))
+	rotated := dotenv.Parse([]byte("A=1\nB=9\n"))
+
+	state := &config.SyncState{Salt: "00112233", Bundles: map[string]config.BundleSync{}}
+	state.Bundles["secrets.envsnap"] = config.BundleSync{Fingerprint: envFingerprint(state, synced)}
+
+	tests := []struct {
+		name    string
+		local   *dotenv.File
+		bundled *dotenv.File
+		want    string
+	}{
+		{name: "same values, different formatting", local: dotenv.Parse([]byte("# c\nB=\"2\"\nA=1\n")), bundled: synced, want: statusInSync},
+		{name: "env edited", local: edited, bundled: synced, want: statusLocalAhead},
+		{name: "bundle rotated", local: synced, bundled: rotated, want: statusBundleAhead},
+		{name: "both changed", local: edited, bundled: rotated, want: statusDiverged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, guessed, _ := compareSync(state, ".env", "./secrets.envsnap", tt.local, tt.bundled)
+			if got != tt.want || guessed {
+				t.Errorf("compareSync() = %s, %v, want %s", got, guessed, tt.want)
+			}
+		})
+	}
+}
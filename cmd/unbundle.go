@@ -5,85 +5,44 @@ import (
 	"os"
 
 	"secretsnap/internal/config"
-	"secretsnap/internal/crypto"
 	"secretsnap/internal/utils"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	unbundleOutFile  string
-	unbundlePass     string
-	unbundlePassFile string
-	unbundlePassMode bool
-	unbundleForce    bool
+	unbundleOutFile string
+	unbundlePass    passFlags
+	unbundleForce   bool
+	unbundleResolve bool
 )
 
 var unbundleCmd = &cobra.Command{
 	Use:   "unbundle [path-to-bundle]",
 	Short: "Decrypt a bundle back to a .env file",
-	Long:  `Decrypt a bundle file back to a .env file. Supports local mode (cached key), passphrase mode, and cloud mode.`,
-	Args:  cobra.ExactArgs(1),
+	Long:  `Decrypt a bundle file back to a .env file. Supports local mode (cached key), passphrase mode, and cloud mode. Without a path, the bundle configured for the project (or the --env environment) is used, composed with any "layers" from .secretsnap.json.`,
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		inputFile := args[0]
-
-		// Validate input file exists
-		if _, err := os.Stat(inputFile); os.IsNotExist(err) {
-			return fmt.Errorf("input file '%s' does not exist", inputFile)
-		}
-
-		encryptedData, err := os.ReadFile(inputFile)
+		// Load project config
+		projectConfig, err := loadProjectConfig()
 		if err != nil {
-			return fmt.Errorf("failed to read input file: %v", err)
+			return err
 		}
 
-		if len(encryptedData) == 0 {
-			return fmt.Errorf("input file '%s' is empty", inputFile)
+		var inputFile string
+		if len(args) == 1 {
+			inputFile = args[0]
 		}
 
-		// Load project config
-		projectConfig, err := config.LoadProjectConfig()
+		env, layers, mode, err := composeEnv(projectConfig, inputFile, unbundlePass.PassphraseSource, unbundlePass.mode, unbundleResolve)
 		if err != nil {
-			return fmt.Errorf("failed to load project config: %v", err)
+			return err
 		}
-
-		// Determine mode based on flags
-		mode := determineUnbundleMode(unbundlePass, unbundlePassFile, unbundlePassMode)
-
-		var decryptedData []byte
-
-		switch mode {
-		case "passphrase":
-			// Passphrase mode
-			passphrase, err := utils.GetPassphrase(unbundlePass, unbundlePassFile)
-			if err != nil {
-				return fmt.Errorf("failed to get passphrase: %v", err)
-			}
-
-			decryptedData, err = crypto.DecryptWithPassphrase(encryptedData, passphrase)
-			if err != nil {
-				return fmt.Errorf("failed to decrypt: %v", err)
-			}
-
-		default:
-			// Local mode (default)
-			projectKey, err := config.GetProjectKey(projectConfig.ProjectName)
-			if err != nil {
-				return fmt.Errorf("no local project key found for '%s'. Fix:\n"+
-					"• On teammate's machine: `secretsnap key export --project %s`\n"+
-					"• Or use passphrase: `--pass`\n"+
-					"• Or use paid pull: `secretsnap login` then `secretsnap pull`",
-					projectConfig.ProjectName, projectConfig.ProjectName)
-			}
-
-			keyBytes, err := crypto.KeyFromBase64(projectKey.KeyB64)
-			if err != nil {
-				return fmt.Errorf("failed to decode project key: %v", err)
-			}
-
-			decryptedData, err = crypto.DecryptWithKey(encryptedData, keyBytes)
-			if err != nil {
-				return fmt.Errorf("failed to decrypt: %v", err)
+		decryptedData := env.Bytes()
+		if len(layers) == 1 {
+			inputFile = layers[0].Path
+			if !unbundleResolve {
+				decryptedData = layers[0].raw
 			}
 		}
 
@@ -94,30 +53,51 @@ var unbundleCmd = &cobra.Command{
 
 		// Write output file with secure permissions
 		if err := os.WriteFile(unbundleOutFile, decryptedData, 0600); err != nil {
-			return fmt.Errorf("failed to write output file: %v", err)
+			return fmt.Errorf("failed to write output file: %w", err)
 		}
 
 		// Check if file permissions are correct and warn if not
 		if info, err := os.Stat(unbundleOutFile); err == nil {
 			if info.Mode().Perm() != 0600 {
-				fmt.Printf("⚠️  Warning: %s has permissions %v, should be 0600\n", unbundleOutFile, info.Mode().Perm())
+				humanf("⚠️  Warning: %s has permissions %v, should be 0600\n", unbundleOutFile, info.Mode().Perm())
 			}
 		}
 
-		fmt.Printf("✅ Decrypted %s to %s\n", inputFile, unbundleOutFile)
+		if len(layers) > 1 {
+			humanf("✅ Composed %d layers to %s\n", len(layers), unbundleOutFile)
+		} else {
+			humanf("✅ Decrypted %s to %s\n", inputFile, unbundleOutFile)
+		}
+		if !unbundleResolve && len(layers) == 1 {
+			recordSync(inputFile, decryptedData)
+		}
 
 		// Track usage and show upsell for free users
 		if mode == "local" || mode == "passphrase" {
 			if err := config.IncrementFreeRun(); err != nil {
 				// Don't fail the command if upsell tracking fails
-				fmt.Fprintf(os.Stderr, "Warning: failed to track usage: %v\n", err)
+				fmt.Fprintf(stderr, "Warning: failed to track usage: %v\n", err)
 			}
 
 			// Show contextual upsell
 			if err := utils.ShowContextualUpsell("unbundle"); err != nil {
 				// Don't fail the command if upsell fails
-				fmt.Fprintf(os.Stderr, "Warning: failed to show upsell: %v\n", err)
+				fmt.Fprintf(stderr, "Warning: failed to show upsell: %v\n", err)
+			}
+		}
+
+		if jsonOutput() {
+			result := unbundleResult{Output: unbundleOutFile, Mode: mode}
+			if len(layers) > 1 {
+				for _, l := range layers {
+					if l.Skipped == "" {
+						result.Layers = append(result.Layers, l.Name)
+					}
+				}
+			} else {
+				result.Input = inputFile
 			}
+			return writeJSON(result)
 		}
 
 		return nil
@@ -126,15 +106,14 @@ var unbundleCmd = &cobra.Command{
 
 func init() {
 	unbundleCmd.Flags().StringVarP(&unbundleOutFile, "out", "o", ".env", "Output file path")
-	unbundleCmd.Flags().StringVarP(&unbundlePass, "pass", "p", "", "Passphrase (prompted if not provided)")
-	unbundleCmd.Flags().StringVarP(&unbundlePassFile, "pass-file", "", "", "Read passphrase from file")
-	unbundleCmd.Flags().BoolVarP(&unbundlePassMode, "pass-mode", "", false, "Use passphrase mode (prompt for passphrase)")
+	addPassFlags(unbundleCmd, &unbundlePass)
 	unbundleCmd.Flags().BoolVarP(&unbundleForce, "force", "f", false, "Overwrite output file if it exists")
+	unbundleCmd.Flags().BoolVarP(&unbundleResolve, "resolve", "", false, "Replace secretsnap:// and ref: values with the referenced values")
 }
 
 // determineUnbundleMode determines the decryption mode based on flags
-func determineUnbundleMode(pass, passFile string, passMode bool) string {
-	if pass != "" || passFile != "" || passMode {
+func determineUnbundleMode(src utils.PassphraseSource, passMode bool) string {
+	if src.Explicit() || passMode {
 		return "passphrase"
 	}
 	return "local"
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"secretsnap/internal/dotenv"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyPass passFlags
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [path-to-bundle]",
+	Short: "Check that a bundle decrypts and its references resolve",
+	Long: `Decrypt a bundle in memory and check that it has no unresolved merge
+conflicts and that its secretsnap:// and ref: values resolve. Nothing is
+written to disk. The bundle defaults to the project's bundle_path.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if workspaceAll {
+			return runWorkspace(cmd, args, &verifyPass)
+		}
+
+		// Load project config
+		projectConfig, err := loadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		inputFile := defaultBundlePath(projectConfig)
+		if len(args) == 1 {
+			inputFile = args[0]
+		}
+
+		encryptedData, err := readBundleFile(inputFile)
+		if err != nil {
+			return err
+		}
+
+		decryptedData, mode, err := decryptBundle(encryptedData, projectConfig, verifyPass.PassphraseSource, verifyPass.mode)
+		if err != nil {
+			return err
+		}
+
+		env := dotenv.Parse(decryptedData)
+		if err := resolveReferences(env, inputFile, projectConfig); err != nil {
+			return err
+		}
+
+		if jsonOutput() {
+			return writeJSON(verifyResult{Bundle: inputFile, Mode: mode, Keys: len(env.Keys())})
+		}
+		humanf("✅ %s decrypts (%s mode, %d keys)\n", inputFile, mode, len(env.Keys()))
+		return nil
+	},
+}
+
+func init() {
+	addPassFlags(verifyCmd, &verifyPass)
+	addWorkspaceFlags(verifyCmd)
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"secretsnap/internal/config"
+	"secretsnap/internal/utils"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	workspaceAll  bool
+	workspaceJobs int
+)
+
+// workspaceResult is the outcome of a command in one project
+type workspaceResult struct {
+	Project string
+	Output  []byte // stdout and stderr, or only stdout with --output json
+	Errors  []byte
+	Err     error
+}
+
+// workspaceJSON is one project in the --output json result of --all
+type workspaceJSON struct {
+	Project string          `json:"project"`
+	OK      bool            `json:"ok"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// addWorkspaceFlags lets cmd run across the projects of the workspace
+func addWorkspaceFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&workspaceAll, "all", "", false, "Run in every project listed in "+config.WorkspaceFile)
+	cmd.Flags().IntVarP(&workspaceJobs, "jobs", "j", runtime.NumCPU(), "Projects to process at once with --all")
+}
+
+// runWorkspace runs cmd with the same arguments in every project of the
+// workspace, at most --jobs at a time. Each run happens in a separate
+// process inside the project directory, so relative paths are per project,
+// except --config-dir. A passphrase named by pass is read once here and
+// handed to every project in SECRETSNAP_PASSPHRASE. Output is printed per
+// project once all have finished.
+func runWorkspace(cmd *cobra.Command, args []string, pass *passFlags) error {
+	cmd.SilenceUsage = true
+	if workspaceJobs < 1 {
+		return fmt.Errorf("--jobs must be at least 1")
+	}
+
+	start := "."
+	if projectDirFlag != "" {
+		start = projectDirFlag
+	}
+	workspace, err := config.FindWorkspace(start)
+	if err != nil {
+		return err
+	}
+	dirs, err := workspace.ProjectDirs()
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		return fmt.Errorf("no projects found in %s", config.WorkspaceFile)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate secretsnap executable: %w", err)
+	}
+	// Children have no stdin or extra descriptors, and --pass would show
+	// up in every child's command line
+	passMode := pass != nil && (pass.mode || pass.Explicit())
+	childEnv := os.Environ()
+	if passMode {
+		passphrase, err := utils.GetPassphrase(pass.PassphraseSource)
+		if err != nil {
+			return err
+		}
+		childEnv = append(childEnv, utils.PassphraseEnv+"="+passphrase)
+	}
+	childArgs, err := workspaceArgs(cmd, args, passMode)
+	if err != nil {
+		return err
+	}
+
+	results := make([]workspaceResult, len(dirs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workspaceJobs && w < len(dirs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				var output, errors bytes.Buffer
+				child := exec.Command(exe, childArgs...)
+				child.Dir = dirs[i]
+				child.Env = childEnv
+				child.Stdout = &output
+				child.Stderr = &output
+				if jsonOutput() {
+					child.Stderr = &errors
+				}
+				results[i] = workspaceResult{Project: workspace.Name(dirs[i]), Err: child.Run()}
+				results[i].Output, results[i].Errors = output.Bytes(), errors.Bytes()
+			}
+		}()
+	}
+	for i := range dirs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if jsonOutput() {
+		return printWorkspaceJSON(cmd, results)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(stdout, "❌ %s\n", result.Project)
+		} else {
+			fmt.Fprintf(stdout, "✅ %s\n", result.Project)
+		}
+		for _, line := range strings.Split(strings.TrimRight(string(result.Output), "\n"), "\n") {
+			if line != "" {
+				fmt.Fprintf(stdout, "   %s\n", line)
+			}
+		}
+	}
+
+	fmt.Fprintf(stdout, "\n📊 %d project(s): %d succeeded, %d failed\n", len(results), len(results)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%s failed in %d of %d project(s)", cmd.Name(), failed, len(results))
+	}
+	return nil
+}
+
+// printWorkspaceJSON prints each project's JSON result and its last error
+func printWorkspaceJSON(cmd *cobra.Command, results []workspaceResult) error {
+	report := []workspaceJSON{}
+	failed := 0
+	for _, result := range results {
+		entry := workspaceJSON{Project: result.Project, OK: result.Err == nil}
+		if json.Valid(result.Output) {
+			entry.Result = result.Output
+		}
+		if result.Err != nil {
+			failed++
+			lines := strings.Split(strings.TrimSpace(string(result.Errors)), "\n")
+			entry.Error = strings.TrimPrefix(lines[len(lines)-1], "Error: ")
+		}
+		report = append(report, entry)
+	}
+
+	if err := writeJSON(report); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s failed in %d of %d project(s)", cmd.Name(), failed, len(results))
+	}
+	return nil
+}
+
+// workspaceArgs rebuilds the command line for a single project: the command
+// path, the flags that were set (except the workspace and passphrase ones)
+// and args. --config-dir is made absolute so it names the same directory in
+// every project, and passMode adds --pass-mode.
+func workspaceArgs(cmd *cobra.Command, args []string, passMode bool) ([]string, error) {
+	childArgs := strings.Fields(cmd.CommandPath())[1:]
+	var err error
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "all", "jobs", "project-dir", "pass", "pass-file", "pass-stdin", "pass-fd", "pass-cmd", "pass-mode":
+			return
+		case "config-dir":
+			dir, absErr := filepath.Abs(flag.Value.String())
+			if absErr != nil {
+				err = fmt.Errorf("failed to resolve --config-dir: %w", absErr)
+			}
+			childArgs = append(childArgs, "--config-dir="+dir)
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range slice.GetSlice() {
+				childArgs = append(childArgs, "--"+flag.Name+"="+value)
+			}
+			return
+		}
+		childArgs = append(childArgs, "--"+flag.Name+"="+flag.Value.String())
+	})
+	if passMode {
+		childArgs = append(childArgs, "--pass-mode")
+	}
+	return append(childArgs, args...), err
+}
@@ -97,7 +97,7 @@ func (c *Client) Login(licenseKey string) (*LoginResponse, error) {
 
 	var loginResp LoginResponse
 	if err := json.Unmarshal(resp, &loginResp); err != nil {
-		return nil, fmt.Errorf("failed to parse login response: %v", err)
+		return nil, fmt.Errorf("failed to parse login response: %w", err)
 	}
 
 	return &loginResp, nil
@@ -112,7 +112,7 @@ func (c *Client) CreateProject(name string) (*Project, error) {
 
 	var project Project
 	if err := json.Unmarshal(resp, &project); err != nil {
-		return nil, fmt.Errorf("failed to parse project response: %v", err)
+		return nil, fmt.Errorf("failed to parse project response: %w", err)
 	}
 
 	return &project, nil
@@ -130,7 +130,7 @@ func (c *Client) BundlePush(projectID string, sizeBytes int) (*BundlePushRespons
 
 	var pushResp BundlePushResponse
 	if err := json.Unmarshal(resp, &pushResp); err != nil {
-		return nil, fmt.Errorf("failed to parse bundle push response: %v", err)
+		return nil, fmt.Errorf("failed to parse bundle push response: %w", err)
 	}
 
 	return &pushResp, nil
@@ -165,7 +165,7 @@ func (c *Client) BundlePullVersion(projectID string, version int) (*BundlePullRe
 
 	var pullResp BundlePullResponse
 	if err := json.Unmarshal(resp, &pullResp); err != nil {
-		return nil, fmt.Errorf("failed to parse bundle pull response: %v", err)
+		return nil, fmt.Errorf("failed to parse bundle pull response: %w", err)
 	}
 
 	return &pullResp, nil
@@ -190,7 +190,7 @@ func (c *Client) GetAuditLogs(projectID string, limit int) ([]AuditLog, error) {
 
 	var logs []AuditLog
 	if err := json.Unmarshal(resp, &logs); err != nil {
-		return nil, fmt.Errorf("failed to parse audit logs response: %v", err)
+		return nil, fmt.Errorf("failed to parse audit logs response: %w", err)
 	}
 
 	return logs, nil
@@ -204,7 +204,7 @@ func (c *Client) UploadToAPI(uploadURL string, data []byte) error {
 
 	req, err := http.NewRequest("POST", uploadURL, bytes.NewReader(data))
 	if err != nil {
-		return fmt.Errorf("failed to create upload request: %v", err)
+		return fmt.Errorf("failed to create upload request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/octet-stream")
@@ -215,13 +215,13 @@ func (c *Client) UploadToAPI(uploadURL string, data []byte) error {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to upload to API: %v", err)
+		return fmt.Errorf("%w: failed to upload to API: %v", ErrNetwork, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API upload failed with status %d: %s", resp.StatusCode, string(body))
+		return statusError("API upload", resp.StatusCode, body)
 	}
 
 	return nil
@@ -235,7 +235,7 @@ func (c *Client) DownloadFromAPI(downloadURL string) ([]byte, error) {
 
 	req, err := http.NewRequest("GET", downloadURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create download request: %v", err)
+		return nil, fmt.Errorf("failed to create download request: %w", err)
 	}
 
 	if c.token != "" {
@@ -244,32 +244,44 @@ func (c *Client) DownloadFromAPI(downloadURL string) ([]byte, error) {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download from API: %v", err)
+		return nil, fmt.Errorf("%w: failed to download from API: %v", ErrNetwork, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API download failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, statusError("API download", resp.StatusCode, body)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	return data, nil
 }
 
+// Ping checks that the API answers HTTP requests. Any response counts,
+// since only reachability is being tested.
+func (c *Client) Ping(timeout time.Duration) error {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(c.baseURL)
+	if err != nil {
+		return fmt.Errorf("%w: request failed: %v", ErrNetwork, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
 func (c *Client) post(path string, body interface{}) ([]byte, error) {
 	jsonData, err := json.Marshal(body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", c.baseURL+path, bytes.NewReader(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -279,17 +291,17 @@ func (c *Client) post(path string, body interface{}) ([]byte, error) {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, fmt.Errorf("%w: request failed: %v", ErrNetwork, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, statusError("API request", resp.StatusCode, respBody)
 	}
 
 	return respBody, nil
@@ -298,7 +310,7 @@ func (c *Client) post(path string, body interface{}) ([]byte, error) {
 func (c *Client) get(url string) ([]byte, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	if c.token != "" {
@@ -307,17 +319,17 @@ func (c *Client) get(url string) ([]byte, error) {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, fmt.Errorf("%w: request failed: %v", ErrNetwork, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, statusError("API request", resp.StatusCode, respBody)
 	}
 
 	return respBody, nil
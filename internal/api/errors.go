@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthorized is returned when the token is missing, expired or rejected
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrPlanRequired is returned when the feature needs a paid plan
+	ErrPlanRequired = errors.New("plan upgrade required")
+
+	// ErrNotFound is returned when the project or bundle does not exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrServer is returned for 5xx responses
+	ErrServer = errors.New("server error")
+
+	// ErrNetwork is returned when the API could not be reached
+	ErrNetwork = errors.New("network error")
+)
+
+// statusError classifies a failed API response
+func statusError(action string, status int, body []byte) error {
+	var kind error
+	switch {
+	case status == http.StatusUnauthorized:
+		kind = ErrUnauthorized
+	case status == http.StatusPaymentRequired:
+		kind = ErrPlanRequired
+	case status == http.StatusForbidden && strings.Contains(strings.ToLower(string(body)), "plan"):
+		kind = ErrPlanRequired
+	case status == http.StatusForbidden:
+		kind = ErrUnauthorized
+	case status == http.StatusNotFound:
+		kind = ErrNotFound
+	case status >= 500:
+		kind = ErrServer
+	default:
+		return fmt.Errorf("%s failed with status %d: %s", action, status, string(body))
+	}
+	return fmt.Errorf("%w: %s failed with status %d: %s", kind, action, status, string(body))
+}
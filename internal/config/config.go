@@ -2,9 +2,12 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,6 +17,99 @@ type ProjectConfig struct {
 	ProjectID   string `json:"project_id"`
 	Mode        string `json:"mode"` // "local", "passphrase", "cloud"
 	BundlePath  string `json:"bundle_path"`
+
+	// Projects maps project names used in secretsnap:// references to
+	// their directories, relative to this file
+	Projects map[string]string `json:"projects,omitempty"`
+
+	// Environments are named variants of the project, selected with --env
+	DefaultEnv   string                 `json:"default_env,omitempty"`
+	Environments map[string]Environment `json:"environments,omitempty"`
+
+	// Layers composes run and unbundle from several sources, lowest
+	// precedence first: "base", "env", "file:<path>", "bundle:<path>"
+	// and "process"
+	Layers []string `json:"layers,omitempty"`
+
+	// Passphrase is the policy for new passphrases in passphrase mode
+	Passphrase *PassphrasePolicy `json:"passphrase,omitempty"`
+
+	// Env and PassFile describe the environment applied by ForEnv
+	Env      string `json:"-"`
+	PassFile string `json:"-"`
+
+	// Dir is the directory holding the config file. Relative paths in the
+	// config are relative to it.
+	Dir string `json:"-"`
+
+	base *ProjectConfig
+}
+
+// Environment overrides project settings for one named environment
+type Environment struct {
+	BundlePath string `json:"bundle_path"`
+	Mode       string `json:"mode,omitempty"`
+	KeyProject string `json:"key_project,omitempty"` // cached key to use instead of the project's
+	PassFile   string `json:"pass_file,omitempty"`
+}
+
+// ForEnv returns the configuration with the named environment applied. An
+// empty name selects default_env, and no environment at all when that is
+// unset too.
+func (c *ProjectConfig) ForEnv(name string) (*ProjectConfig, error) {
+	if name == "" {
+		name = c.DefaultEnv
+	}
+	if name == "" {
+		return c, nil
+	}
+
+	env, ok := c.Environments[name]
+	if !ok {
+		if len(c.Environments) == 0 {
+			return nil, fmt.Errorf("unknown environment '%s': no environments are declared in %s", name, projectFile)
+		}
+		return nil, fmt.Errorf("unknown environment '%s' (declared: %s)", name, strings.Join(c.EnvNames(), ", "))
+	}
+
+	applied := *c
+	applied.Env = name
+	applied.base = c
+	if env.BundlePath != "" {
+		applied.BundlePath = env.BundlePath
+	}
+	if env.Mode != "" {
+		applied.Mode = env.Mode
+	}
+	if env.KeyProject != "" {
+		applied.ProjectName = env.KeyProject
+	}
+	if env.PassFile != "" {
+		applied.PassFile = c.Path(env.PassFile)
+		if env.Mode == "" {
+			applied.Mode = "passphrase"
+		}
+	}
+
+	return &applied, nil
+}
+
+// Base returns the configuration without any environment applied
+func (c *ProjectConfig) Base() *ProjectConfig {
+	if c.base != nil {
+		return c.base
+	}
+	return c
+}
+
+// EnvNames returns the declared environment names, sorted
+func (c *ProjectConfig) EnvNames() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // ProjectKey represents a cached project key
@@ -42,83 +138,180 @@ type UsageStats struct {
 }
 
 var (
-	configDir     string
-	projectFile   string
+	projectFile   = ".secretsnap.json"
+	gitignoreFile = ".gitignore"
 	keysFile      string
-	globalDir     string
 	tokenFile     string
-	gitignoreFile string
 	usageFile     string
+	settingsFile  string
 )
 
 func init() {
-	home, err := os.UserHomeDir()
+	// Commands report a failure through SetGlobalDir again once flags
+	// are parsed
+	SetGlobalDir("")
+}
+
+var (
+	// ErrNoProjectConfig is returned when no project config is found
+	ErrNoProjectConfig = errors.New("no .secretsnap.json found in this directory or any parent up to the repository root. Run `secretsnap init`")
+
+	// ErrNoProjectKey is returned when the key cache has no key for a project
+	ErrNoProjectKey = errors.New("no local project key found")
+
+	// ErrNotLoggedIn is returned by cloud commands when no token is saved
+	ErrNotLoggedIn = errors.New("not logged in")
+
+	// ErrKeystoreLocked is returned when keys.json is encrypted and not
+	// unlocked for this session
+	ErrKeystoreLocked = errors.New("the key store is locked. Run 'secretsnap keystore unlock'")
+)
+
+// projectDir is the project directory given with SetProjectDir. When
+// empty, the project is discovered from the current directory.
+var projectDir string
+
+// SetProjectDir uses dir as the project directory instead of discovering it
+func SetProjectDir(dir string) error {
+	if dir == "" {
+		projectDir = ""
+		return nil
+	}
+	info, err := os.Stat(dir)
 	if err != nil {
-		panic(fmt.Sprintf("failed to get home directory: %v", err))
+		return fmt.Errorf("project directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("project directory %s is not a directory", dir)
 	}
+	projectDir = dir
+	return nil
+}
 
-	configDir = filepath.Join(home, ".secretsnap")
-	projectFile = ".secretsnap.json"
-	keysFile = filepath.Join(configDir, "keys.json")
-	globalDir = configDir
-	tokenFile = filepath.Join(globalDir, "token")
-	gitignoreFile = ".gitignore"
-	usageFile = filepath.Join(globalDir, "usage.json")
+// FindProjectDir walks up from dir to the first directory holding a project
+// config, like git does for .git. The search stops at the repository root
+// (the first directory containing .git) or the filesystem root.
+func FindProjectDir(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, projectFile)); err == nil {
+			return dir, true
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// ProjectDir returns the directory of the current project: the one given
+// with SetProjectDir, the one discovered from the current directory, or the
+// current directory when there is no project yet
+func ProjectDir() string {
+	if projectDir != "" {
+		return projectDir
+	}
+	if dir, ok := FindProjectDir("."); ok {
+		return relativePath(dir)
+	}
+	return "."
+}
+
+// DefaultProjectConfig returns the configuration init creates in dir
+func DefaultProjectConfig(dir string) *ProjectConfig {
+	name := filepath.Base(getCurrentDir())
+	if abs, err := filepath.Abs(dir); err == nil {
+		name = filepath.Base(abs)
+	}
+	return &ProjectConfig{
+		ProjectName: name,
+		ProjectID:   "local",
+		Mode:        "local",
+		BundlePath:  "secrets.envsnap",
+		Dir:         dir,
+	}
 }
 
-// EnsureConfigDir creates the global config directory with proper permissions
-func EnsureConfigDir() error {
-	return os.MkdirAll(configDir, 0700)
+// FindProjectConfig loads the current project's configuration without
+// creating one. It returns ErrNoProjectConfig when there is none.
+func FindProjectConfig() (*ProjectConfig, error) {
+	dir := ProjectDir()
+	config, err := ReadProjectConfig(dir)
+	if os.IsNotExist(err) {
+		return nil, ErrNoProjectConfig
+	}
+	return config, err
 }
 
-// LoadProjectConfig loads the project configuration from the current directory
+// LoadProjectConfig loads the current project's configuration, creating a
+// default one in the project directory when there is none
 func LoadProjectConfig() (*ProjectConfig, error) {
-	if _, err := os.Stat(projectFile); os.IsNotExist(err) {
-		// Create default project config
-		projectName := filepath.Base(getCurrentDir())
-		config := &ProjectConfig{
-			ProjectName: projectName,
-			ProjectID:   "local",
-			Mode:        "local",
-			BundlePath:  "secrets.envsnap",
-		}
+	config, err := FindProjectConfig()
+	if errors.Is(err, ErrNoProjectConfig) {
+		config = DefaultProjectConfig(ProjectDir())
 		if err := SaveProjectConfig(config); err != nil {
 			return nil, err
 		}
 		return config, nil
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config file: %w", err)
+	}
 
-	data, err := os.ReadFile(projectFile)
+	return config, nil
+}
+
+// ReadProjectConfig loads the project configuration in dir without
+// creating one when it is missing
+func ReadProjectConfig(dir string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(filepath.Join(dir, projectFile))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read project config file: %v", err)
+		return nil, err
 	}
 
 	var config ProjectConfig
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse project config file: %v", err)
+		return nil, fmt.Errorf("failed to parse project config file: %w", err)
 	}
+	config.Dir = dir
 
 	return &config, nil
 }
 
-// SaveProjectConfig saves the project configuration to the current directory
+// SaveProjectConfig saves the project configuration to its directory
 func SaveProjectConfig(config *ProjectConfig) error {
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal project config: %v", err)
+		return fmt.Errorf("failed to marshal project config: %w", err)
 	}
 
-	if err := os.WriteFile(projectFile, data, 0600); err != nil {
-		return fmt.Errorf("failed to write project config file: %v", err)
+	if err := os.WriteFile(filepath.Join(config.Dir, projectFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write project config file: %w", err)
 	}
 
 	return nil
 }
 
-// LoadKeysConfig loads the global keys configuration
+// Path resolves a path from the config relative to the config's directory
+func (c *ProjectConfig) Path(path string) string {
+	if path == "" || filepath.IsAbs(path) || c.Dir == "" {
+		return path
+	}
+	return relativePath(filepath.Join(c.Dir, path))
+}
+
+// LoadKeysConfig loads the global keys configuration, creating it when
+// missing
 func LoadKeysConfig() (*KeysConfig, error) {
 	if err := EnsureConfigDir(); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %v", err)
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
 	if _, err := os.Stat(keysFile); os.IsNotExist(err) {
@@ -132,20 +325,43 @@ func LoadKeysConfig() (*KeysConfig, error) {
 		return config, nil
 	}
 
+	return ReadKeysConfig()
+}
+
+// ReadKeysConfig reads the global keys configuration without creating it.
+// An encrypted key store must be unlocked first (ErrKeystoreLocked).
+func ReadKeysConfig() (*KeysConfig, error) {
+	if errGlobalDir != nil {
+		return nil, errGlobalDir
+	}
+
 	data, err := os.ReadFile(keysFile)
+	if os.IsNotExist(err) {
+		return &KeysConfig{Projects: make(map[string]ProjectKey)}, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read keys file: %v", err)
+		return nil, fmt.Errorf("failed to read keys file: %w", err)
+	}
+
+	if locked, ok := parseLockedKeys(data); ok {
+		if data, err = locked.open(); err != nil {
+			return nil, err
+		}
 	}
 
 	var config KeysConfig
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse keys file: %v", err)
+		return nil, fmt.Errorf("failed to parse keys file: %w", err)
+	}
+	if config.Projects == nil {
+		config.Projects = make(map[string]ProjectKey)
 	}
 
 	return &config, nil
 }
 
-// SaveKeysConfig saves the global keys configuration
+// SaveKeysConfig saves the global keys configuration. An encrypted key
+// store stays encrypted, and does not need to be unlocked to be saved.
 func SaveKeysConfig(config *KeysConfig) error {
 	if err := EnsureConfigDir(); err != nil {
 		return err
@@ -153,18 +369,31 @@ func SaveKeysConfig(config *KeysConfig) error {
 
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal keys config: %v", err)
+		return fmt.Errorf("failed to marshal keys config: %w", err)
 	}
 
+	if current, err := os.ReadFile(keysFile); err == nil {
+		if locked, ok := parseLockedKeys(current); ok {
+			if data, err = locked.seal(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writeKeysFile(data)
+}
+
+// writeKeysFile replaces keys.json
+func writeKeysFile(data []byte) error {
 	// Atomic write: write to temp file first, then rename
 	tempFile := keysFile + ".tmp"
 	if err := os.WriteFile(tempFile, data, 0600); err != nil {
-		return fmt.Errorf("failed to write temp keys file: %v", err)
+		return fmt.Errorf("failed to write temp keys file: %w", err)
 	}
 
 	if err := os.Rename(tempFile, keysFile); err != nil {
 		os.Remove(tempFile) // Clean up temp file
-		return fmt.Errorf("failed to rename keys file: %v", err)
+		return fmt.Errorf("failed to rename keys file: %w", err)
 	}
 
 	return nil
@@ -179,7 +408,7 @@ func GetProjectKey(projectName string) (*ProjectKey, error) {
 
 	key, exists := keys.Projects[projectName]
 	if !exists {
-		return nil, fmt.Errorf("no key found for project '%s'", projectName)
+		return nil, fmt.Errorf("%w for project '%s'", ErrNoProjectKey, projectName)
 	}
 
 	return &key, nil
@@ -200,15 +429,33 @@ func SaveProjectKey(projectName string, key *ProjectKey) error {
 	return SaveKeysConfig(keys)
 }
 
+// DeleteProjectKey removes a project key from the cache
+func DeleteProjectKey(projectName string) error {
+	keys, err := LoadKeysConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, exists := keys.Projects[projectName]; !exists {
+		return fmt.Errorf("%w for project '%s'", ErrNoProjectKey, projectName)
+	}
+
+	delete(keys.Projects, projectName)
+	return SaveKeysConfig(keys)
+}
+
 // LoadToken loads the JWT token for cloud mode
 func LoadToken() (string, error) {
+	if errGlobalDir != nil {
+		return "", errGlobalDir
+	}
 	if _, err := os.Stat(tokenFile); os.IsNotExist(err) {
 		return "", nil
 	}
 
 	data, err := os.ReadFile(tokenFile)
 	if err != nil {
-		return "", fmt.Errorf("failed to read token file: %v", err)
+		return "", fmt.Errorf("failed to read token file: %w", err)
 	}
 
 	return string(data), nil
@@ -221,26 +468,30 @@ func SaveToken(token string) error {
 	}
 
 	if err := os.WriteFile(tokenFile, []byte(token), 0600); err != nil {
-		return fmt.Errorf("failed to write token file: %v", err)
+		return fmt.Errorf("failed to write token file: %w", err)
 	}
 
 	return nil
 }
 
+// GitignoreEntries are the patterns init adds to .gitignore
+var GitignoreEntries = []string{
+	".secretsnap.key",
+	"secrets.envsnap.key",
+	".secretsnap/",
+}
+
 // EnsureGitignoreEntries ensures the necessary entries are in .gitignore
 func EnsureGitignoreEntries() error {
-	entries := []string{
-		".secretsnap.key",
-		"secrets.envsnap.key",
-		".secretsnap/",
-	}
+	entries := GitignoreEntries
+	gitignoreFile := GetGitignorePath()
 
 	// Read existing .gitignore
 	var existingContent []byte
 	if _, err := os.Stat(gitignoreFile); err == nil {
 		existingContent, err = os.ReadFile(gitignoreFile)
 		if err != nil {
-			return fmt.Errorf("failed to read .gitignore: %v", err)
+			return fmt.Errorf("failed to read .gitignore: %w", err)
 		}
 	}
 
@@ -263,7 +514,7 @@ func EnsureGitignoreEntries() error {
 		}
 
 		if err := os.WriteFile(gitignoreFile, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write .gitignore: %v", err)
+			return fmt.Errorf("failed to write .gitignore: %w", err)
 		}
 	}
 
@@ -279,6 +530,22 @@ func getCurrentDir() string {
 	return dir
 }
 
+// relativePath returns path relative to the current directory when possible
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, abs); err == nil {
+		return rel
+	}
+	return path
+}
+
 func containsLine(content, line string) bool {
 	// Simple check - in a real implementation you might want more sophisticated parsing
 	return contains(content, line)
@@ -297,7 +564,22 @@ func endsWithNewline(s string) bool {
 
 // GetProjectConfigPath returns the path to the project config file
 func GetProjectConfigPath() string {
-	return projectFile
+	return relativePath(filepath.Join(ProjectDir(), projectFile))
+}
+
+// GetTokenPath returns the path to the token file
+func GetTokenPath() string {
+	return tokenFile
+}
+
+// GetUsagePath returns the path to the usage stats file
+func GetUsagePath() string {
+	return usageFile
+}
+
+// GetGitignorePath returns the path to the project's .gitignore
+func GetGitignorePath() string {
+	return relativePath(filepath.Join(ProjectDir(), gitignoreFile))
 }
 
 // GetKeysConfigPath returns the path to the keys config file
@@ -308,7 +590,7 @@ func GetKeysConfigPath() string {
 // LoadUsageStats loads usage statistics
 func LoadUsageStats() (*UsageStats, error) {
 	if err := EnsureConfigDir(); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %v", err)
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
 	if _, err := os.Stat(usageFile); os.IsNotExist(err) {
@@ -326,12 +608,12 @@ func LoadUsageStats() (*UsageStats, error) {
 
 	data, err := os.ReadFile(usageFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read usage file: %v", err)
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
 	}
 
 	var stats UsageStats
 	if err := json.Unmarshal(data, &stats); err != nil {
-		return nil, fmt.Errorf("failed to parse usage file: %v", err)
+		return nil, fmt.Errorf("failed to parse usage file: %w", err)
 	}
 
 	return &stats, nil
@@ -345,18 +627,18 @@ func SaveUsageStats(stats *UsageStats) error {
 
 	data, err := json.MarshalIndent(stats, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal usage stats: %v", err)
+		return fmt.Errorf("failed to marshal usage stats: %w", err)
 	}
 
 	// Atomic write: write to temp file first, then rename
 	tempFile := usageFile + ".tmp"
 	if err := os.WriteFile(tempFile, data, 0600); err != nil {
-		return fmt.Errorf("failed to write temp usage file: %v", err)
+		return fmt.Errorf("failed to write temp usage file: %w", err)
 	}
 
 	if err := os.Rename(tempFile, usageFile); err != nil {
 		os.Remove(tempFile) // Clean up temp file
-		return fmt.Errorf("failed to rename usage file: %v", err)
+		return fmt.Errorf("failed to rename usage file: %w", err)
 	}
 
 	return nil
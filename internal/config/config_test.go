@@ -0,0 +1,341 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"secretsnap/internal/crypto"
+)
+
+func TestForEnv(t *testing.T) {
+	base := &ProjectConfig{
+		ProjectName: "app",
+		Mode:        "local",
+		BundlePath:  "secrets.envsnap",
+		Environments: map[string]Environment{
+			"dev":     {BundlePath: "secrets.dev.envsnap"},
+			"prod":    {BundlePath: "secrets.prod.envsnap", PassFile: "prod.pass"},
+			"staging": {BundlePath: "secrets.staging.envsnap", KeyProject: "app-staging"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		env        string
+		defaultEnv string
+		bundle     string
+		mode       string
+		project    string
+		wantErr    string
+	}{
+		{name: "no environment", bundle: "secrets.envsnap", mode: "local", project: "app"},
+		{name: "default env", defaultEnv: "dev", bundle: "secrets.dev.envsnap", mode: "local", project: "app"},
+		{name: "explicit beats default", env: "staging", defaultEnv: "dev", bundle: "secrets.staging.envsnap", mode: "local", project: "app-staging"},
+		{name: "pass file implies passphrase", env: "prod", bundle: "secrets.prod.envsnap", mode: "passphrase", project: "app"},
+		{name: "unknown", env: "qa", wantErr: "declared: dev, prod, staging"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := *base
+			config.DefaultEnv = tt.defaultEnv
+
+			applied, err := config.ForEnv(tt.env)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ForEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ForEnv() error = %v", err)
+			}
+			if applied.BundlePath != tt.bundle || applied.Mode != tt.mode || applied.ProjectName != tt.project {
+				t.Errorf("ForEnv() = %s/%s/%s, want %s/%s/%s", applied.BundlePath, applied.Mode, applied.ProjectName, tt.bundle, tt.mode, tt.project)
+			}
+		})
+	}
+
+	if base.BundlePath != "secrets.envsnap" {
+		t.Error("ForEnv() modified the original config")
+	}
+}
+
+func TestFindProjectDir(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{".git", "app/src/deep", "nested/.git", "nested/src"} {
+		os.MkdirAll(filepath.Join(root, dir), 0700)
+	}
+	os.WriteFile(filepath.Join(root, projectFile), []byte("{}"), 0600)
+	os.WriteFile(filepath.Join(root, "app", projectFile), []byte("{}"), 0600)
+
+	tests := []struct {
+		name  string
+		start string
+		want  string
+		found bool
+	}{
+		{name: "project root", start: "app", want: "app", found: true},
+		{name: "subdirectory", start: "app/src/deep", want: "app", found: true},
+		{name: "repository root", start: ".", want: ".", found: true},
+		{name: "stops at nested repository", start: "nested/src", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := FindProjectDir(filepath.Join(root, tt.start))
+			if found != tt.found {
+				t.Fatalf("FindProjectDir() found = %v, want %v", found, tt.found)
+			}
+			if found && got != filepath.Join(root, tt.want) {
+				t.Errorf("FindProjectDir() = %s, want %s", got, filepath.Join(root, tt.want))
+			}
+		})
+	}
+}
+
+func TestWorkspaceProjectDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"services/api", "services/web", "services/docs", "libs/auth"} {
+		os.MkdirAll(filepath.Join(root, dir), 0700)
+	}
+	for _, dir := range []string{"services/api", "services/web", "libs/auth"} {
+		os.WriteFile(filepath.Join(root, dir, projectFile), []byte("{}"), 0600)
+	}
+
+	workspace := &Workspace{Dir: root, Projects: []string{"services/*", "libs/auth", "services/api"}}
+	dirs, err := workspace.ProjectDirs()
+	if err != nil {
+		t.Fatalf("ProjectDirs() error = %v", err)
+	}
+	var names []string
+	for _, dir := range dirs {
+		names = append(names, workspace.Name(dir))
+	}
+	if got, want := strings.Join(names, ","), "libs/auth,services/api,services/web"; got != want {
+		t.Errorf("ProjectDirs() = %s, want %s", got, want)
+	}
+
+	workspace.Projects = []string{"services/docs"}
+	if _, err := workspace.ProjectDirs(); err == nil {
+		t.Errorf("ProjectDirs() accepted a named directory without %s", projectFile)
+	}
+}
+
+func TestSetGlobalDir(t *testing.T) {
+	t.Cleanup(func() { SetGlobalDir("") })
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("SECRETSNAP_HOME", "")
+
+	if err := SetGlobalDir("/ci/secretsnap"); err != nil || GetKeysConfigPath() != "/ci/secretsnap/keys.json" || GetUsagePath() != "/ci/secretsnap/usage.json" {
+		t.Errorf("--config-dir: keys %s, usage %s, err %v", GetKeysConfigPath(), GetUsagePath(), err)
+	}
+
+	t.Setenv("SECRETSNAP_HOME", "/opt/secretsnap")
+	if err := SetGlobalDir(""); err != nil || GetTokenPath() != "/opt/secretsnap/token" {
+		t.Errorf("SECRETSNAP_HOME: token %s, err %v", GetTokenPath(), err)
+	}
+
+	t.Setenv("SECRETSNAP_HOME", "")
+	if runtime.GOOS == "linux" {
+		if err := SetGlobalDir(""); err != nil || GetKeysConfigPath() != "/xdg/config/secretsnap/keys.json" || GetUsagePath() != "/home/dev/.local/state/secretsnap/usage.json" {
+			t.Errorf("XDG: keys %s, usage %s, err %v", GetKeysConfigPath(), GetUsagePath(), err)
+		}
+
+		t.Setenv("HOME", "")
+		t.Setenv("XDG_CONFIG_HOME", "")
+		if err := SetGlobalDir(""); err == nil || !strings.Contains(err.Error(), "SECRETSNAP_HOME") {
+			t.Errorf("no home: err = %v, want a hint about SECRETSNAP_HOME", err)
+		}
+		if err := EnsureConfigDir(); err == nil {
+			t.Errorf("EnsureConfigDir() succeeded without a directory")
+		}
+	}
+}
+
+func TestMigrateLegacyDir(t *testing.T) {
+	home := t.TempDir()
+	t.Cleanup(func() { SetGlobalDir("") })
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+
+	legacy := filepath.Join(home, legacyDirName)
+	os.MkdirAll(legacy, 0700)
+	os.WriteFile(filepath.Join(legacy, "keys.json"), []byte("old keys"), 0600)
+	os.WriteFile(filepath.Join(legacy, "token"), []byte("old token"), 0600)
+
+	// An explicit directory never receives the user's files
+	t.Setenv("SECRETSNAP_HOME", filepath.Join(home, "ci"))
+	if err := SetGlobalDir(""); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := MigrateLegacyDir(); err != nil || got != "" {
+		t.Fatalf("MigrateLegacyDir() with SECRETSNAP_HOME = %q, %v, want nothing moved", got, err)
+	}
+	if err := SetGlobalDir(filepath.Join(home, "flag")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := MigrateLegacyDir(); err != nil || got != "" {
+		t.Fatalf("MigrateLegacyDir() with --config-dir = %q, %v, want nothing moved", got, err)
+	}
+	for _, name := range []string{"keys.json", "token"} {
+		if _, err := os.Stat(filepath.Join(legacy, name)); err != nil {
+			t.Fatalf("legacy %s was touched: %v", name, err)
+		}
+	}
+
+	if runtime.GOOS != "linux" {
+		return
+	}
+	t.Setenv("SECRETSNAP_HOME", "")
+	if err := SetGlobalDir(""); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(GetKeysConfigPath()), 0700)
+	os.WriteFile(GetKeysConfigPath(), []byte("new keys"), 0600)
+
+	// keys.json already exists in the new location, the token still moves
+	if got, err := MigrateLegacyDir(); err != nil || got != legacy {
+		t.Fatalf("MigrateLegacyDir() = %q, %v, want %q", got, err, legacy)
+	}
+	if data, _ := os.ReadFile(GetKeysConfigPath()); string(data) != "new keys" {
+		t.Errorf("keys.json = %q, want it kept", data)
+	}
+	if data, _ := os.ReadFile(GetTokenPath()); string(data) != "old token" {
+		t.Errorf("token = %q, want it moved", data)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "keys.json")); err != nil {
+		t.Errorf("legacy keys.json was removed: %v", err)
+	}
+}
+
+func TestPassphrasePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     *PassphrasePolicy
+		passphrase string
+		wantErr    string
+	}{
+		{"default ok", nil, "correct horse battery", ""},
+		{"empty", nil, "", "empty"},
+		{"default too short", nil, "hunter2", "too short"},
+		{"custom length", &PassphrasePolicy{MinLength: 4}, "abcd", ""},
+		{"too weak", &PassphrasePolicy{MinClasses: 3}, "all lowercase words", "too weak"},
+		{"mixed", &PassphrasePolicy{MinClasses: 3}, "Mixed case and 42", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.passphrase)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Check() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Check() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := (&PassphrasePolicy{WorkFactor: 30}).ScryptWorkFactor(); err == nil {
+		t.Errorf("ScryptWorkFactor() accepted 30")
+	}
+	if factor, err := (*PassphrasePolicy)(nil).ScryptWorkFactor(); err != nil || factor != DefaultScryptWorkFactor {
+		t.Errorf("ScryptWorkFactor() = %d, %v, want the default", factor, err)
+	}
+}
+
+func TestKeystoreLockUnlock(t *testing.T) {
+	t.Cleanup(func() { SetGlobalDir("") })
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv(IdentityEnv, "")
+	if err := SetGlobalDir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	key := ProjectKey{KeyB64: "a2V5", KeyID: "id"}
+	if err := SaveProjectKey("app", &key); err != nil {
+		t.Fatal(err)
+	}
+
+	master, err := crypto.GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EncryptKeystore(master.Recipient()); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(GetKeysConfigPath()); strings.Contains(string(data), key.KeyB64) {
+		t.Fatalf("keys.json still holds the key in plaintext")
+	}
+	if _, err := GetProjectKey("app"); !errors.Is(err, ErrKeystoreLocked) {
+		t.Fatalf("GetProjectKey on a locked store = %v, want ErrKeystoreLocked", err)
+	}
+
+	other, _ := crypto.GenerateIdentity()
+	if _, err := UnlockKeystore(other, time.Hour); err == nil {
+		t.Fatalf("UnlockKeystore with the wrong identity succeeded")
+	}
+
+	// An expired session is removed; without a runtime directory there is
+	// nowhere to keep one
+	if _, err := UnlockKeystore(master, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := GetKeystoreState(); state.Unlocked {
+		t.Fatalf("state after the session expired = %+v", state)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(runtimeDir, "secretsnap")); len(entries) != 0 {
+		t.Fatalf("expired session left %d file(s) behind", len(entries))
+	}
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	if _, err := UnlockKeystore(master, time.Hour); err == nil || !strings.Contains(err.Error(), IdentityEnv) {
+		t.Fatalf("UnlockKeystore without XDG_RUNTIME_DIR = %v, want a hint about %s", err, IdentityEnv)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	if _, err := UnlockKeystore(master, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving while unlocked keeps the store encrypted
+	if err := SaveProjectKey("api", &ProjectKey{KeyB64: "YXBp", KeyID: "id2"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetProjectKey("app"); err != nil || got.KeyB64 != key.KeyB64 {
+		t.Fatalf("GetProjectKey after unlock = %v, %v", got, err)
+	}
+	if state, _ := GetKeystoreState(); !state.Encrypted || !state.Unlocked {
+		t.Fatalf("state after unlock = %+v", state)
+	}
+
+	// A new master opens the same keys under a new store identity; the old
+	// master and the session no longer do
+	before, _ := readLockedKeys()
+	if err := ChangeKeystoreMaster(master, other.Recipient()); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := readLockedKeys(); after.Recipient == before.Recipient {
+		t.Fatalf("ChangeKeystoreMaster kept the store identity")
+	}
+	if _, err := GetProjectKey("app"); !errors.Is(err, ErrKeystoreLocked) {
+		t.Fatalf("GetProjectKey after passwd = %v, want ErrKeystoreLocked", err)
+	}
+	if _, err := UnlockKeystore(master, time.Hour); err == nil {
+		t.Fatalf("UnlockKeystore with the old master succeeded")
+	}
+	if _, err := UnlockKeystore(other, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if keys, err := LoadKeysConfig(); err != nil || len(keys.Projects) != 2 {
+		t.Fatalf("LoadKeysConfig after passwd = %v, %v", keys, err)
+	}
+}
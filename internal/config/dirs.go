@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// legacyDirName is the single directory used for all global state before
+// the XDG layout
+const legacyDirName = ".secretsnap"
+
+// Global state lives in three directories. With SECRETSNAP_HOME or
+// --config-dir they are all the same directory.
+var (
+	configDir string // keys, token and settings
+	stateDir  string // usage stats
+	cacheDir  string // data that can be recreated
+
+	// errGlobalDir is why the directories could not be located
+	errGlobalDir error
+
+	// explicitGlobalDir is set when SECRETSNAP_HOME or --config-dir chose
+	// the directory
+	explicitGlobalDir bool
+)
+
+// SetGlobalDir locates the global directories: dir when given (the
+// --config-dir flag), then SECRETSNAP_HOME, then the XDG base directories
+// on Linux and ~/.secretsnap elsewhere
+func SetGlobalDir(dir string) error {
+	if dir == "" {
+		dir = os.Getenv("SECRETSNAP_HOME")
+	}
+
+	errGlobalDir = nil
+	explicitGlobalDir = dir != ""
+	switch {
+	case dir != "":
+		configDir, stateDir, cacheDir = dir, dir, dir
+	case runtime.GOOS == "linux":
+		var err error
+		if configDir, err = xdgDir("XDG_CONFIG_HOME", ".config"); err == nil {
+			if stateDir, err = xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state")); err == nil {
+				cacheDir, err = xdgDir("XDG_CACHE_HOME", ".cache")
+			}
+		}
+		errGlobalDir = err
+	default:
+		home, err := os.UserHomeDir()
+		configDir = filepath.Join(home, legacyDirName)
+		stateDir, cacheDir = configDir, configDir
+		errGlobalDir = err
+	}
+
+	if errGlobalDir != nil {
+		errGlobalDir = fmt.Errorf("cannot locate the secretsnap directory (%v). Set SECRETSNAP_HOME or use --config-dir", errGlobalDir)
+		configDir, stateDir, cacheDir = "", "", ""
+	}
+
+	keysFile = filepath.Join(configDir, "keys.json")
+	tokenFile = filepath.Join(configDir, "token")
+	usageFile = filepath.Join(stateDir, "usage.json")
+	settingsFile = filepath.Join(configDir, "settings.json")
+	return errGlobalDir
+}
+
+// xdgDir returns $env/secretsnap, or ~/fallback/secretsnap when env is unset
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "secretsnap"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback, "secretsnap"), nil
+}
+
+// MigrateLegacyDir moves the files of ~/.secretsnap to the XDG directories
+// the first time they are used. A directory set with SECRETSNAP_HOME or
+// --config-dir is often a throwaway one, so nothing is moved there. It
+// returns the old directory when files were moved.
+func MigrateLegacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil || errGlobalDir != nil || explicitGlobalDir {
+		return "", nil
+	}
+	legacy := filepath.Join(home, legacyDirName)
+	if legacy == configDir {
+		return "", nil
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return "", nil
+	}
+
+	if err := EnsureConfigDir(); err != nil {
+		return "", err
+	}
+
+	// Each file moves on its own; one that already exists in the new
+	// location is never overwritten
+	moves := map[string]string{
+		filepath.Join(legacy, "keys.json"):  keysFile,
+		filepath.Join(legacy, "token"):      tokenFile,
+		filepath.Join(legacy, "usage.json"): usageFile,
+	}
+	moved := false
+	for from, to := range moves {
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if _, err := os.Stat(to); err == nil {
+			continue
+		}
+		if err := moveFile(from, to); err != nil {
+			return "", fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+		}
+		moved = true
+	}
+
+	// Only removed when nothing else is left in it
+	os.Remove(legacy)
+	if !moved {
+		return "", nil
+	}
+	return legacy, nil
+}
+
+// moveFile renames from to to, or copies and removes it when they are on
+// different file systems
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	tmp := to + ".tmp"
+	if err := os.WriteFile(tmp, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, to); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(from)
+}
+
+// EnsureConfigDir creates the global directories with proper permissions
+func EnsureConfigDir() error {
+	if errGlobalDir != nil {
+		return errGlobalDir
+	}
+	for _, dir := range []string{configDir, stateDir, cacheDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetConfigDir returns the global config directory
+func GetConfigDir() string {
+	return configDir
+}
+
+// GetStateDir returns the global state directory
+func GetStateDir() string {
+	return stateDir
+}
+
+// GetCacheDir returns the global cache directory
+func GetCacheDir() string {
+	return cacheDir
+}
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"secretsnap/internal/crypto"
+
+	"filippo.io/age"
+)
+
+// IdentityEnv names an age identity file that unlocks a key store
+// encrypted with `keystore lock --identity`, without an unlock session
+const IdentityEnv = "SECRETSNAP_IDENTITY"
+
+// lockedKeys is keys.json once the key store is encrypted. The keys are
+// encrypted to a store identity of their own, and the store identity is
+// encrypted with the master passphrase or identity. Unlocking caches the
+// store identity for the session, and new keys can be saved with the
+// public Recipient alone. Changing the master replaces the store identity.
+type lockedKeys struct {
+	Keystore  int    `json:"keystore"`  // format version, 1
+	Recipient string `json:"recipient"` // store identity's public key
+	Master    []byte `json:"master"`    // store identity, encrypted with the master
+	Keys      []byte `json:"keys"`      // KeysConfig JSON, encrypted to Recipient
+}
+
+// keystoreSession is the unlock cached for the session
+type keystoreSession struct {
+	Recipient string    `json:"recipient"`
+	Identity  string    `json:"identity"`
+	Expires   time.Time `json:"expires"`
+
+	identity *age.X25519Identity
+}
+
+// KeystoreState describes the key store
+type KeystoreState struct {
+	Encrypted bool
+	Unlocked  bool
+	Expires   time.Time // end of the unlock session
+}
+
+// parseLockedKeys reports whether data is an encrypted key store
+func parseLockedKeys(data []byte) (*lockedKeys, bool) {
+	var locked lockedKeys
+	if err := json.Unmarshal(data, &locked); err != nil || locked.Keystore == 0 {
+		return nil, false
+	}
+	return &locked, true
+}
+
+// readLockedKeys returns the encrypted key store, or nil when keys.json is
+// plaintext or missing
+func readLockedKeys() (*lockedKeys, error) {
+	if errGlobalDir != nil {
+		return nil, errGlobalDir
+	}
+	data, err := os.ReadFile(keysFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys file: %w", err)
+	}
+	locked, _ := parseLockedKeys(data)
+	return locked, nil
+}
+
+// open decrypts the keys with the session's store identity, or with the
+// identity file named by SECRETSNAP_IDENTITY
+func (l *lockedKeys) open() ([]byte, error) {
+	var identity *age.X25519Identity
+	session, err := l.session()
+	if err != nil {
+		return nil, err
+	}
+	if session != nil {
+		identity = session.identity
+	} else {
+		path := os.Getenv(IdentityEnv)
+		if path == "" {
+			return nil, ErrKeystoreLocked
+		}
+		master, err := crypto.ReadIdentityFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if identity, err = l.unwrap(master); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := crypto.DecryptWithIdentity(l.Keys, identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt key store: %w", err)
+	}
+	return data, nil
+}
+
+// seal encrypts keys JSON into a copy of the store
+func (l *lockedKeys) seal(keysJSON []byte) ([]byte, error) {
+	recipient, err := age.ParseX25519Recipient(l.Recipient)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key store recipient: %w", err)
+	}
+	sealed := *l
+	if sealed.Keys, err = crypto.EncryptToRecipient(keysJSON, recipient); err != nil {
+		return nil, fmt.Errorf("failed to encrypt key store: %w", err)
+	}
+	return json.MarshalIndent(sealed, "", "  ")
+}
+
+// unwrap decrypts the store identity with the master identity
+func (l *lockedKeys) unwrap(master age.Identity) (*age.X25519Identity, error) {
+	data, err := crypto.DecryptWithIdentity(l.Master, master)
+	if err != nil {
+		if errors.Is(err, crypto.ErrWrongKey) {
+			return nil, fmt.Errorf("failed to unlock key store: wrong master passphrase or identity: %w", err)
+		}
+		return nil, fmt.Errorf("failed to unlock key store: %w", err)
+	}
+	identity, err := age.ParseX25519Identity(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("key store identity is corrupt: %w", err)
+	}
+	return identity, nil
+}
+
+// session returns the unexpired unlock session for this store, or nil.
+// A session that expired or belongs to another store is removed.
+func (l *lockedKeys) session() (*keystoreSession, error) {
+	path, err := keystoreSessionPath()
+	if err != nil {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key store session: %w", err)
+	}
+
+	var session keystoreSession
+	if json.Unmarshal(data, &session) == nil && session.Recipient == l.Recipient && time.Now().Before(session.Expires) {
+		if session.identity, err = age.ParseX25519Identity(session.Identity); err == nil {
+			return &session, nil
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove key store session: %w", err)
+	}
+	return nil, nil
+}
+
+// keystoreSessionPath is where an unlock is cached: the per-login runtime
+// directory, which is not persisted or backed up. There is no fallback, as
+// every other directory would keep the store identity on disk next to
+// keys.json. The name depends on keys.json's path so several stores do not
+// share a session.
+func keystoreSessionPath() (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("cannot keep the key store unlocked without XDG_RUNTIME_DIR. Set %s to an identity file instead", IdentityEnv)
+	}
+	home, _ := os.UserHomeDir()
+	for _, persistent := range []string{home, configDir, stateDir, cacheDir} {
+		if persistent != "" && isWithin(dir, persistent) {
+			return "", fmt.Errorf("XDG_RUNTIME_DIR (%s) is inside %s, which is persisted. Set %s to an identity file instead", dir, persistent, IdentityEnv)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(keysFile))
+	return filepath.Join(dir, "secretsnap", "keystore-"+hex.EncodeToString(sum[:4])+".json"), nil
+}
+
+// isWithin reports whether path is dir or below it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && (rel == "." || filepath.IsLocal(rel))
+}
+
+// GetKeystoreState reports whether the key store is encrypted and unlocked
+func GetKeystoreState() (*KeystoreState, error) {
+	locked, err := readLockedKeys()
+	if err != nil || locked == nil {
+		return &KeystoreState{}, err
+	}
+
+	state := &KeystoreState{Encrypted: true}
+	session, err := locked.session()
+	if err != nil {
+		return nil, err
+	}
+	if session != nil {
+		state.Unlocked, state.Expires = true, session.Expires
+	}
+	return state, nil
+}
+
+// EncryptKeystore encrypts the plaintext keys.json for a master recipient.
+// The file is replaced atomically, like every save, and a leftover unlock
+// session is removed.
+func EncryptKeystore(master age.Recipient) error {
+	if locked, err := readLockedKeys(); err != nil {
+		return err
+	} else if locked != nil {
+		return fmt.Errorf("the key store is already encrypted. Use 'secretsnap keystore passwd' to change the master")
+	}
+
+	keys, err := LoadKeysConfig()
+	if err != nil {
+		return err
+	}
+	keysJSON, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal keys config: %w", err)
+	}
+
+	identity, err := crypto.GenerateIdentity()
+	if err != nil {
+		return err
+	}
+	locked := &lockedKeys{Keystore: 1, Recipient: identity.Recipient().String()}
+	if locked.Master, err = crypto.EncryptToRecipient([]byte(identity.String()), master); err != nil {
+		return fmt.Errorf("failed to encrypt key store identity: %w", err)
+	}
+
+	data, err := locked.seal(keysJSON)
+	if err != nil {
+		return err
+	}
+	if err := writeKeysFile(data); err != nil {
+		return err
+	}
+	return LockKeystore()
+}
+
+// UnlockKeystore opens the key store with the master identity and caches
+// the unlock for ttl. It returns the time the session expires.
+func UnlockKeystore(master age.Identity, ttl time.Duration) (time.Time, error) {
+	locked, err := readLockedKeys()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if locked == nil {
+		return time.Time{}, fmt.Errorf("the key store is not encrypted. Run 'secretsnap keystore lock' to encrypt it")
+	}
+
+	path, err := keystoreSessionPath()
+	if err != nil {
+		return time.Time{}, err
+	}
+	identity, err := locked.unwrap(master)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if _, err := crypto.DecryptWithIdentity(locked.Keys, identity); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decrypt key store: %w", err)
+	}
+
+	session := keystoreSession{
+		Recipient: locked.Recipient,
+		Identity:  identity.String(),
+		Expires:   time.Now().Add(ttl),
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to marshal key store session: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return time.Time{}, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		return time.Time{}, fmt.Errorf("failed to write key store session: %w", err)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return time.Time{}, fmt.Errorf("failed to write key store session: %w", err)
+	}
+	return session.Expires, nil
+}
+
+// LockKeystore ends the unlock session. Without a runtime directory there
+// is no session to end.
+func LockKeystore() error {
+	if errGlobalDir != nil {
+		return errGlobalDir
+	}
+	path, err := keystoreSessionPath()
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove key store session: %w", err)
+	}
+	return nil
+}
+
+// ChangeKeystoreMaster re-encrypts the keys to a new store identity, which
+// is encrypted for the new master. A store identity that leaked, from an
+// old session or the old master, no longer opens keys.json. The unlock
+// session ends.
+func ChangeKeystoreMaster(old age.Identity, master age.Recipient) error {
+	locked, err := readLockedKeys()
+	if err != nil {
+		return err
+	}
+	if locked == nil {
+		return fmt.Errorf("the key store is not encrypted. Run 'secretsnap keystore lock' to encrypt it")
+	}
+
+	oldIdentity, err := locked.unwrap(old)
+	if err != nil {
+		return err
+	}
+	keysJSON, err := crypto.DecryptWithIdentity(locked.Keys, oldIdentity)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt key store: %w", err)
+	}
+
+	identity, err := crypto.GenerateIdentity()
+	if err != nil {
+		return err
+	}
+	rotated := &lockedKeys{Keystore: 1, Recipient: identity.Recipient().String()}
+	if rotated.Master, err = crypto.EncryptToRecipient([]byte(identity.String()), master); err != nil {
+		return fmt.Errorf("failed to encrypt key store identity: %w", err)
+	}
+
+	data, err := rotated.seal(keysJSON)
+	if err != nil {
+		return err
+	}
+	if err := writeKeysFile(data); err != nil {
+		return err
+	}
+	return LockKeystore()
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// Passphrase policy defaults and limits
+const (
+	DefaultMinPassphraseLength = 12
+	DefaultScryptWorkFactor    = 18 // age's default
+	MinScryptWorkFactor        = 10
+	MaxScryptWorkFactor        = 22 // the most age accepts when decrypting
+)
+
+// PassphrasePolicy is the "passphrase" section of .secretsnap.json. It
+// applies when a bundle is encrypted with a new passphrase.
+type PassphrasePolicy struct {
+	// MinLength is the minimum number of characters (default 12)
+	MinLength int `json:"min_length,omitempty"`
+
+	// MinClasses is how many of lowercase, uppercase, digits and other
+	// characters must appear (default 1)
+	MinClasses int `json:"min_classes,omitempty"`
+
+	// WorkFactor is the scrypt cost as log2(N), 10 to 22 (default 18).
+	// Each step doubles the time needed to try a passphrase.
+	WorkFactor int `json:"work_factor,omitempty"`
+}
+
+// Check returns an error describing why passphrase does not meet the
+// policy. A nil policy uses the defaults.
+func (p *PassphrasePolicy) Check(passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("passphrase is empty")
+	}
+
+	minLength := DefaultMinPassphraseLength
+	minClasses := 1
+	if p != nil {
+		if p.MinLength > 0 {
+			minLength = p.MinLength
+		}
+		if p.MinClasses > 0 {
+			minClasses = p.MinClasses
+		}
+	}
+
+	if length := len([]rune(passphrase)); length < minLength {
+		return fmt.Errorf("passphrase is too short: %d characters, at least %d required", length, minLength)
+	}
+	if classes := characterClasses(passphrase); classes < minClasses {
+		return fmt.Errorf("passphrase is too weak: uses %d of lowercase, uppercase, digits and symbols, at least %d required", classes, minClasses)
+	}
+	return nil
+}
+
+// ScryptWorkFactor returns the configured scrypt work factor
+func (p *PassphrasePolicy) ScryptWorkFactor() (int, error) {
+	if p == nil || p.WorkFactor == 0 {
+		return DefaultScryptWorkFactor, nil
+	}
+	if p.WorkFactor < MinScryptWorkFactor || p.WorkFactor > MaxScryptWorkFactor {
+		return 0, fmt.Errorf("passphrase work_factor must be between %d and %d, got %d", MinScryptWorkFactor, MaxScryptWorkFactor, p.WorkFactor)
+	}
+	return p.WorkFactor, nil
+}
+
+// characterClasses counts the kinds of characters in s
+func characterClasses(s string) int {
+	var lower, upper, digit, other bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	count := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Settings are user preferences kept in settings.json in the config
+// directory. Flags and environment variables override them per run.
+type Settings struct {
+	Quiet    bool `json:"quiet,omitempty"`     // suppress progress messages
+	Plain    bool `json:"plain,omitempty"`     // no emoji in output
+	NoUpsell bool `json:"no_upsell,omitempty"` // never show upgrade banners
+}
+
+// SettingNames are the names accepted by Get and Set
+var SettingNames = []string{"plain", "quiet", "upsell"}
+
+// Get returns the value of a named setting
+func (s *Settings) Get(name string) (bool, error) {
+	switch name {
+	case "quiet":
+		return s.Quiet, nil
+	case "plain":
+		return s.Plain, nil
+	case "upsell":
+		return !s.NoUpsell, nil
+	}
+	return false, fmt.Errorf("unknown setting '%s' (known: %s)", name, strings.Join(SettingNames, ", "))
+}
+
+// Set changes a named setting. value is true/false, on/off or yes/no.
+func (s *Settings) Set(name, value string) error {
+	var b bool
+	switch value {
+	case "on", "yes":
+		b = true
+	case "off", "no":
+		b = false
+	default:
+		var err error
+		if b, err = strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid value '%s' for %s: use on or off", value, name)
+		}
+	}
+
+	switch name {
+	case "quiet":
+		s.Quiet = b
+	case "plain":
+		s.Plain = b
+	case "upsell":
+		s.NoUpsell = !b
+	default:
+		return fmt.Errorf("unknown setting '%s' (known: %s)", name, strings.Join(SettingNames, ", "))
+	}
+	return nil
+}
+
+// LoadSettings loads the user settings; a missing file means the defaults
+func LoadSettings() (*Settings, error) {
+	if errGlobalDir != nil {
+		return nil, errGlobalDir
+	}
+
+	data, err := os.ReadFile(settingsFile)
+	if os.IsNotExist(err) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var settings Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+	return &settings, nil
+}
+
+// SaveSettings saves the user settings
+func SaveSettings(settings *Settings) error {
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	// Atomic write: write to temp file first, then rename
+	tempFile := settingsFile + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temp settings file: %w", err)
+	}
+
+	if err := os.Rename(tempFile, settingsFile); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to rename settings file: %w", err)
+	}
+
+	return nil
+}
+
+// GetSettingsPath returns the path to the settings file
+func GetSettingsPath() string {
+	return settingsFile
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stateFile records the last sync between plaintext files and bundles. It
+// lives in the project's .secretsnap/ directory, which init git-ignores.
+func stateFile() string {
+	return filepath.Join(ProjectDir(), ".secretsnap", "state.json")
+}
+
+// SyncState remembers what each bundle contained when it was last bundled
+// or unbundled, so status can tell which side changed since
+type SyncState struct {
+	// Salt keys the fingerprints so they cannot be compared across projects
+	Salt    string                `json:"salt"`
+	Bundles map[string]BundleSync `json:"bundles"`
+	// Remote is the last cloud version pushed or pulled, by project ID
+	Remote map[string]int `json:"remote,omitempty"`
+}
+
+// BundleSync is the state of one bundle at its last sync
+type BundleSync struct {
+	Fingerprint string    `json:"fingerprint"`
+	SyncedAt    time.Time `json:"synced_at"`
+}
+
+// LoadSyncState loads the project's sync state, or an empty one
+func LoadSyncState() (*SyncState, error) {
+	state := &SyncState{}
+
+	data, err := os.ReadFile(stateFile())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to parse state file: %w", err)
+		}
+	}
+
+	if state.Bundles == nil {
+		state.Bundles = make(map[string]BundleSync)
+	}
+	if state.Remote == nil {
+		state.Remote = make(map[string]int)
+	}
+	return state, nil
+}
+
+// SaveSyncState saves the project's sync state
+func SaveSyncState(state *SyncState) error {
+	path := stateFile()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	return nil
+}
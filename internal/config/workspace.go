@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// WorkspaceFile lists the sub-projects of a monorepo. It lives at the
+// repository root.
+const WorkspaceFile = ".secretsnap.workspace.json"
+
+// Workspace is the content of the workspace file
+type Workspace struct {
+	// Projects are directories or glob patterns, relative to the workspace
+	// file, that hold a .secretsnap.json
+	Projects []string `json:"projects"`
+
+	// Dir is the directory holding the workspace file
+	Dir string `json:"-"`
+}
+
+// FindWorkspace walks up from dir to the workspace file, stopping at the
+// repository root
+func FindWorkspace(dir string) (*Workspace, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, WorkspaceFile))
+		if err == nil {
+			workspace := &Workspace{Dir: dir}
+			if err := json.Unmarshal(data, workspace); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", WorkspaceFile, err)
+			}
+			return workspace, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", WorkspaceFile, err)
+		}
+
+		parent := filepath.Dir(dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil || parent == dir {
+			return nil, fmt.Errorf("no %s found in this directory or any parent up to the repository root", WorkspaceFile)
+		}
+		dir = parent
+	}
+}
+
+// ProjectDirs expands the workspace's projects into the directories that
+// hold a project config, sorted and without duplicates
+func (w *Workspace) ProjectDirs() ([]string, error) {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, pattern := range w.Projects {
+		matches, err := filepath.Glob(filepath.Join(w.Dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid project pattern '%s': %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("project pattern '%s' in %s matches nothing", pattern, WorkspaceFile)
+		}
+
+		for _, dir := range matches {
+			if _, err := os.Stat(filepath.Join(dir, projectFile)); err != nil {
+				// Globs may match plain directories; named projects must exist
+				if !strings.ContainsAny(pattern, "*?[") {
+					return nil, fmt.Errorf("project '%s' in %s has no %s", pattern, WorkspaceFile, projectFile)
+				}
+				continue
+			}
+			if !seen[dir] {
+				seen[dir] = true
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// Name returns a project directory relative to the workspace
+func (w *Workspace) Name(dir string) string {
+	if rel, err := filepath.Rel(w.Dir, dir); err == nil {
+		return rel
+	}
+	return dir
+}
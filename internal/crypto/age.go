@@ -10,25 +10,29 @@ import (
 	"filippo.io/age"
 )
 
-// EncryptWithPassphrase encrypts data using age with a passphrase
-func EncryptWithPassphrase(data []byte, passphrase string) ([]byte, error) {
+// EncryptWithPassphrase encrypts data using age with a passphrase. A
+// workFactor of 0 uses age's default scrypt cost.
+func EncryptWithPassphrase(data []byte, passphrase string, workFactor int) ([]byte, error) {
 	recipient, err := age.NewScryptRecipient(passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create recipient: %v", err)
+		return nil, fmt.Errorf("failed to create recipient: %w", err)
+	}
+	if workFactor > 0 {
+		recipient.SetWorkFactor(workFactor)
 	}
 
 	var buf bytes.Buffer
 	writer, err := age.Encrypt(&buf, recipient)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create encrypt writer: %v", err)
+		return nil, fmt.Errorf("failed to create encrypt writer: %w", err)
 	}
 
 	if _, err := writer.Write(data); err != nil {
-		return nil, fmt.Errorf("failed to write data: %v", err)
+		return nil, fmt.Errorf("failed to write data: %w", err)
 	}
 
 	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close writer: %v", err)
+		return nil, fmt.Errorf("failed to close writer: %w", err)
 	}
 
 	return buf.Bytes(), nil
@@ -38,17 +42,17 @@ func EncryptWithPassphrase(data []byte, passphrase string) ([]byte, error) {
 func DecryptWithPassphrase(encryptedData []byte, passphrase string) ([]byte, error) {
 	identity, err := age.NewScryptIdentity(passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create identity: %v", err)
+		return nil, fmt.Errorf("failed to create identity: %w", err)
 	}
 
 	reader, err := age.Decrypt(bytes.NewReader(encryptedData), identity)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create decrypt reader: %v", err)
+		return nil, decryptError(err)
 	}
 
 	data, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read decrypted data: %v", err)
+		return nil, fmt.Errorf("%w: failed to read decrypted data: %v", ErrInvalidBundle, err)
 	}
 
 	return data, nil
@@ -67,21 +71,21 @@ func EncryptWithKey(data []byte, key []byte) ([]byte, error) {
 
 	recipient, err := age.NewScryptRecipient(passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create recipient: %v", err)
+		return nil, fmt.Errorf("failed to create recipient: %w", err)
 	}
 
 	var buf bytes.Buffer
 	writer, err := age.Encrypt(&buf, recipient)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create encrypt writer: %v", err)
+		return nil, fmt.Errorf("failed to create encrypt writer: %w", err)
 	}
 
 	if _, err := writer.Write(data); err != nil {
-		return nil, fmt.Errorf("failed to write data: %v", err)
+		return nil, fmt.Errorf("failed to write data: %w", err)
 	}
 
 	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close writer: %v", err)
+		return nil, fmt.Errorf("failed to close writer: %w", err)
 	}
 
 	return buf.Bytes(), nil
@@ -98,17 +102,17 @@ func DecryptWithKey(encryptedData []byte, key []byte) ([]byte, error) {
 
 	identity, err := age.NewScryptIdentity(passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create identity: %v", err)
+		return nil, fmt.Errorf("failed to create identity: %w", err)
 	}
 
 	reader, err := age.Decrypt(bytes.NewReader(encryptedData), identity)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create decrypt reader: %v", err)
+		return nil, decryptError(err)
 	}
 
 	data, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read decrypted data: %v", err)
+		return nil, fmt.Errorf("%w: failed to read decrypted data: %v", ErrInvalidBundle, err)
 	}
 
 	return data, nil
@@ -118,7 +122,7 @@ func DecryptWithKey(encryptedData []byte, key []byte) ([]byte, error) {
 func GenerateProjectKey() ([]byte, error) {
 	key := make([]byte, 32)
 	if _, err := rand.Read(key); err != nil {
-		return nil, fmt.Errorf("failed to generate random key: %v", err)
+		return nil, fmt.Errorf("failed to generate random key: %w", err)
 	}
 	return key, nil
 }
@@ -128,7 +132,7 @@ func GenerateKeyID() (string, error) {
 	// Generate 16 random bytes for the key ID
 	idBytes := make([]byte, 16)
 	if _, err := rand.Read(idBytes); err != nil {
-		return "", fmt.Errorf("failed to generate key ID: %v", err)
+		return "", fmt.Errorf("failed to generate key ID: %w", err)
 	}
 	
 	// Convert to base64 for a readable ID
@@ -144,7 +148,7 @@ func GenerateDataKey() ([]byte, error) {
 func KeyFromBase64(keyB64 string) ([]byte, error) {
 	key, err := base64.StdEncoding.DecodeString(keyB64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode base64 key: %v", err)
+		return nil, fmt.Errorf("failed to decode base64 key: %w", err)
 	}
 	
 	if len(key) != 32 {
@@ -158,3 +162,8 @@ func KeyFromBase64(keyB64 string) ([]byte, error) {
 func KeyToBase64(key []byte) string {
 	return base64.StdEncoding.EncodeToString(key)
 }
+
+// IsBundle reports whether data looks like an age-encrypted bundle
+func IsBundle(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("age-encryption.org/v1\n"))
+}
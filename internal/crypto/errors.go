@@ -0,0 +1,25 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+
+	"filippo.io/age"
+)
+
+var (
+	// ErrWrongKey is returned when the key or passphrase does not open a bundle
+	ErrWrongKey = errors.New("wrong key or passphrase")
+
+	// ErrInvalidBundle is returned for data that is not a readable bundle
+	ErrInvalidBundle = errors.New("not a valid bundle")
+)
+
+// decryptError classifies an error from age.Decrypt
+func decryptError(err error) error {
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return ErrWrongKey
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+}
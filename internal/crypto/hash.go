@@ -0,0 +1,44 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// ValueHasher computes keyed hashes of secret values so they can be
+// compared without keeping the plaintext around
+type ValueHasher struct {
+	key []byte
+}
+
+// NewValueHasher creates a hasher with a fresh random key. Hashes from
+// different hashers are not comparable, so they never leak across runs.
+func NewValueHasher() (*ValueHasher, error) {
+	key, err := GenerateDataKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate hash key: %w", err)
+	}
+	return &ValueHasher{key: key}, nil
+}
+
+// NewValueHasherWithKey creates a hasher with a caller-supplied key
+func NewValueHasherWithKey(key []byte) *ValueHasher {
+	return &ValueHasher{key: key}
+}
+
+// Hash returns the hex-encoded HMAC-SHA256 of value
+func (h *ValueHasher) Hash(value string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// KeyFingerprint identifies a key without revealing it: "SHA256:" and the
+// unpadded base64 of its SHA-256, like ssh-keygen -l
+func KeyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,74 @@
+package crypto
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+)
+
+// GenerateIdentity creates a new age X25519 identity
+func GenerateIdentity() (*age.X25519Identity, error) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate identity: %w", err)
+	}
+	return identity, nil
+}
+
+// ReadIdentityFile reads the first X25519 identity (AGE-SECRET-KEY-1...)
+// from an age identity file, as written by age-keygen
+func ReadIdentityFile(path string) (*age.X25519Identity, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open identity file: %w", err)
+	}
+	defer file.Close()
+
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity file %s: %w", path, err)
+	}
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			return x25519, nil
+		}
+	}
+	return nil, fmt.Errorf("no X25519 identity in %s", path)
+}
+
+// EncryptToRecipient encrypts data for an age recipient
+func EncryptToRecipient(data []byte, recipient age.Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encrypt writer: %w", err)
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write data: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close writer: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecryptWithIdentity decrypts data with an age identity
+func DecryptWithIdentity(encryptedData []byte, identity age.Identity) ([]byte, error) {
+	reader, err := age.Decrypt(bytes.NewReader(encryptedData), identity)
+	if err != nil {
+		return nil, decryptError(err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read decrypted data: %v", ErrInvalidBundle, err)
+	}
+
+	return data, nil
+}
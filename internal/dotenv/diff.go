@@ -0,0 +1,46 @@
+package dotenv
+
+import "sort"
+
+// Change kinds reported by Diff
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is one key that differs between two files
+type Change struct {
+	Key  string `json:"key"`
+	Kind string `json:"change"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// Diff compares the final values of two files and returns the changes,
+// sorted by key
+func Diff(old, new *File) []Change {
+	oldValues := old.Map()
+	newValues := new.Map()
+
+	var changes []Change
+	for key, oldValue := range oldValues {
+		newValue, ok := newValues[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: key, Kind: Removed, Old: oldValue})
+		case newValue != oldValue:
+			changes = append(changes, Change{Key: key, Kind: Changed, Old: oldValue, New: newValue})
+		}
+	}
+	for key, newValue := range newValues {
+		if _, ok := oldValues[key]; !ok {
+			changes = append(changes, Change{Key: key, Kind: Added, New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
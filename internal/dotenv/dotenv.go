@@ -0,0 +1,310 @@
+package dotenv
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kind describes what a parsed line contains
+type Kind int
+
+const (
+	// Blank is an empty or whitespace-only line
+	Blank Kind = iota
+	// Comment is a full-line comment starting with #
+	Comment
+	// Assignment is a KEY=VALUE line
+	Assignment
+	// Invalid is a non-comment line without an '=', or a merge conflict
+	// marker
+	Invalid
+)
+
+// Line is a single logical line of a .env file. Quoted values may span
+// several physical lines, in which case Raw contains all of them.
+type Line struct {
+	Number   int    // 1-based number of the first physical line
+	Raw      string // text as read, without the final newline
+	Kind     Kind
+	Export   bool   // line starts with "export "
+	Key      string // key as written, trimmed
+	Value    string // decoded value
+	RawValue string // value as written, including quotes
+	Quote    byte   // '"', '\'' or 0 for unquoted values
+	Comment  string // trailing inline comment, including the '#'
+}
+
+// Multiline reports whether the value spans more than one physical line
+func (l Line) Multiline() bool {
+	return strings.Contains(l.Raw, "\n")
+}
+
+// File is a parsed .env file with its lines kept in order
+type File struct {
+	Lines []Line
+}
+
+var validKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidKey reports whether key is a portable environment variable name
+func ValidKey(key string) bool {
+	return validKey.MatchString(key)
+}
+
+// Parse parses .env data. It never fails: lines it cannot understand are
+// returned with Kind Invalid so callers can decide how to report them.
+func Parse(data []byte) *File {
+	physical := strings.Split(string(data), "\n")
+	if len(physical) > 0 && physical[len(physical)-1] == "" {
+		physical = physical[:len(physical)-1]
+	}
+
+	file := &File{}
+	for i := 0; i < len(physical); i++ {
+		line := Line{Number: i + 1, Raw: physical[i]}
+		text := strings.TrimSpace(strings.TrimSuffix(physical[i], "\r"))
+
+		switch {
+		case text == "":
+			line.Kind = Blank
+		case strings.HasPrefix(text, "#"):
+			line.Kind = Comment
+		case isConflictMarker(text):
+			// "=======" must not become an assignment with an empty key
+			line.Kind = Invalid
+			line.Key = text
+		default:
+			if rest, ok := cutExport(text); ok {
+				line.Export = true
+				text = rest
+			}
+
+			idx := strings.Index(text, "=")
+			if idx < 0 {
+				line.Kind = Invalid
+				line.Key = text
+				break
+			}
+
+			line.Kind = Assignment
+			line.Key = strings.TrimSpace(text[:idx])
+			rest := strings.TrimLeft(text[idx+1:], " \t")
+
+			if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+				// Quoted values may continue on the following lines
+				quote := rest[0]
+				end := closingQuote(rest, quote)
+				for end < 0 && i+1 < len(physical) {
+					i++
+					line.Raw += "\n" + physical[i]
+					rest += "\n" + strings.TrimSuffix(physical[i], "\r")
+					end = closingQuote(rest, quote)
+				}
+				if end < 0 {
+					// Unterminated quote: keep everything as the value
+					line.RawValue = rest
+					line.Value = rest[1:]
+					line.Quote = quote
+					break
+				}
+				line.RawValue = rest[:end+1]
+				line.Quote = quote
+				if quote == '"' {
+					line.Value = unescape(rest[1:end])
+				} else {
+					line.Value = rest[1:end]
+				}
+				if tail := strings.TrimSpace(rest[end+1:]); strings.HasPrefix(tail, "#") {
+					line.Comment = tail
+				}
+				break
+			}
+
+			// Unquoted values end at an inline " #" comment
+			if idx := strings.Index(rest, " #"); idx >= 0 {
+				line.Comment = strings.TrimSpace(rest[idx+1:])
+				rest = rest[:idx]
+			} else if idx := strings.Index(rest, "\t#"); idx >= 0 {
+				line.Comment = strings.TrimSpace(rest[idx+1:])
+				rest = rest[:idx]
+			}
+			line.RawValue = strings.TrimSpace(rest)
+			line.Value = line.RawValue
+		}
+
+		file.Lines = append(file.Lines, line)
+	}
+
+	return file
+}
+
+// Entries returns the assignment lines in file order, including duplicates
+func (f *File) Entries() []Line {
+	var entries []Line
+	for _, line := range f.Lines {
+		if line.Kind == Assignment {
+			entries = append(entries, line)
+		}
+	}
+	return entries
+}
+
+// Keys returns each key once, in the order it first appears
+func (f *File) Keys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, line := range f.Entries() {
+		if !seen[line.Key] {
+			seen[line.Key] = true
+			keys = append(keys, line.Key)
+		}
+	}
+	return keys
+}
+
+// Map returns the final value for every key. Later definitions win.
+func (f *File) Map() map[string]string {
+	values := make(map[string]string)
+	for _, line := range f.Entries() {
+		values[line.Key] = line.Value
+	}
+	return values
+}
+
+// Get returns the final value for key
+func (f *File) Get(key string) (string, bool) {
+	value, ok := f.Map()[key]
+	return value, ok
+}
+
+// Environ returns KEY=value strings suitable for exec.Cmd.Env
+func (f *File) Environ() []string {
+	values := f.Map()
+	var env []string
+	for _, key := range f.Keys() {
+		env = append(env, key+"="+values[key])
+	}
+	return env
+}
+
+// Set assigns value to key. The last existing definition is rewritten in
+// place, keeping its export prefix and inline comment; a new key is
+// appended at the end of the file.
+func (f *File) Set(key, value string) {
+	for i := len(f.Lines) - 1; i >= 0; i-- {
+		line := f.Lines[i]
+		if line.Kind != Assignment || line.Key != key {
+			continue
+		}
+
+		raw := FormatLine(key, value)
+		if line.Export {
+			raw = "export " + raw
+		}
+		if line.Comment != "" {
+			raw += " " + line.Comment
+		}
+		f.Lines[i] = parseLine(raw, line.Number)
+		return
+	}
+
+	f.Lines = append(f.Lines, parseLine(FormatLine(key, value), len(f.Lines)+1))
+}
+
+// parseLine parses a single formatted line, keeping the given line number
+func parseLine(raw string, number int) Line {
+	line := Parse([]byte(raw)).Lines[0]
+	line.Number = number
+	return line
+}
+
+// Bytes renders the file back to .env text
+func (f *File) Bytes() []byte {
+	var b strings.Builder
+	for _, line := range f.Lines {
+		b.WriteString(line.Raw)
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// FormatValue renders a value so that Parse returns it unchanged.
+// Simple values are written bare; anything else is double-quoted.
+func FormatValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	if strings.ContainsAny(value, " \t\r\n\"'#\\$`") {
+		return `"` + escape(value) + `"`
+	}
+	return value
+}
+
+// FormatLine renders a KEY=value line
+func FormatLine(key, value string) string {
+	return key + "=" + FormatValue(value)
+}
+
+func cutExport(text string) (string, bool) {
+	for _, prefix := range []string{"export ", "export\t"} {
+		if strings.HasPrefix(text, prefix) {
+			return strings.TrimSpace(text[len(prefix):]), true
+		}
+	}
+	return text, false
+}
+
+// closingQuote returns the index of the quote that closes s[0], or -1
+func closingQuote(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\', '$', '`':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func escape(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"$", `\$`,
+		"`", "\\`",
+	)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,250 @@
+package dotenv
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		key      string
+		expected string
+		found    bool
+	}{
+		{name: "plain value", input: "FOO=bar\n", key: "FOO", expected: "bar", found: true},
+		{name: "export prefix", input: "export FOO=bar\n", key: "FOO", expected: "bar", found: true},
+		{name: "double quoted", input: `FOO="hello world"` + "\n", key: "FOO", expected: "hello world", found: true},
+		{name: "double quoted escapes", input: `FOO="a\nb\"c"` + "\n", key: "FOO", expected: "a\nb\"c", found: true},
+		{name: "single quoted is literal", input: `FOO='a\nb'` + "\n", key: "FOO", expected: `a\nb`, found: true},
+		{name: "inline comment", input: "FOO=bar # note\n", key: "FOO", expected: "bar", found: true},
+		{name: "hash without space", input: "FOO=bar#baz\n", key: "FOO", expected: "bar#baz", found: true},
+		{name: "multi-line quoted", input: "FOO=\"line1\nline2\"\nBAR=x\n", key: "FOO", expected: "line1\nline2", found: true},
+		{name: "crlf", input: "FOO=bar\r\n", key: "FOO", expected: "bar", found: true},
+		{name: "last definition wins", input: "FOO=a\nFOO=b\n", key: "FOO", expected: "b", found: true},
+		{name: "comment only", input: "# FOO=bar\n", key: "FOO", found: false},
+		{name: "line without equals", input: "FOO\n", key: "FOO", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found := Parse([]byte(tt.input)).Get(tt.key)
+			if found != tt.found {
+				t.Fatalf("Get(%q) found = %v, want %v", tt.key, found, tt.found)
+			}
+			if value != tt.expected {
+				t.Errorf("Get(%q) = %q, want %q", tt.key, value, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatValueRoundTrip(t *testing.T) {
+	values := []string{"", "simple", "with space", "quote\"inside", "multi\nline", `back\slash`, "dollar$HOME", "hash #x"}
+
+	for _, value := range values {
+		line := FormatLine("KEY", value)
+		got, _ := Parse([]byte(line + "\n")).Get("KEY")
+		if got != value {
+			t.Errorf("round trip of %q via %q = %q", value, line, got)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		codes []string
+	}{
+		{name: "clean file", input: "# comment\nFOO=bar\nBAZ=\"a b\"\n", codes: nil},
+		{name: "duplicate key", input: "FOO=a\nFOO=b\n", codes: []string{CodeDuplicateKey}},
+		{name: "invalid name", input: "1FOO=a\n", codes: []string{CodeInvalidName}},
+		{name: "trailing whitespace", input: "FOO=a  \n", codes: []string{CodeWhitespace}},
+		{name: "unquoted space", input: "FOO=a b\n", codes: []string{CodeUnquotedSpace}},
+		{name: "crlf", input: "FOO=a\r\nBAR=b\r\n", codes: []string{CodeCRLF}},
+		{name: "no equals", input: "FOO\n", codes: []string{CodeDroppedByRun}},
+		{name: "space before equals", input: "FOO =a\n", codes: []string{CodeDroppedByRun}},
+		{name: "mixed export", input: "export FOO=a\nBAR=b\n", codes: []string{CodeDroppedByRun, CodeMixedExport}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Lint([]byte(tt.input))
+			if len(issues) != len(tt.codes) {
+				t.Fatalf("Lint() returned %d issues (%+v), want %v", len(issues), issues, tt.codes)
+			}
+			for i, issue := range issues {
+				if issue.Code != tt.codes[i] {
+					t.Errorf("issue %d code = %s, want %s", i, issue.Code, tt.codes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLintNeverLeaksValues(t *testing.T) {
+	input := "FOO=s3cr3t value\nFOO=s3cr3t\n 1BAD=s3cr3t\n"
+	for _, issue := range Lint([]byte(input)) {
+		if strings.Contains(issue.Message, "s3cr3t") {
+			t.Errorf("issue message leaks value: %s", issue.Message)
+		}
+	}
+}
+
+func TestFix(t *testing.T) {
+	input := "  FOO=bar  \r\nBAZ=hello world # note\nQUX = x\nKEEP=\"as is\"\n"
+	expected := "FOO=bar\nBAZ=\"hello world\" # note\nQUX=x\nKEEP=\"as is\"\n"
+
+	fixed, changed := Fix([]byte(input))
+	if string(fixed) != expected {
+		t.Errorf("Fix() = %q, want %q", fixed, expected)
+	}
+	if changed != 3 {
+		t.Errorf("Fix() changed %d lines, want 3", changed)
+	}
+	if issues := Lint(fixed); len(issues) != 0 {
+		t.Errorf("Lint() after Fix() = %+v, want no issues", issues)
+	}
+}
+
+func TestExample(t *testing.T) {
+	input := "# Database\nDATABASE_URL=postgres://u:p@db:5432/app # primary\nPORT=8080\nDEBUG=false\nPORT=9090\nEMPTY=\nTOKEN=\"s3cr3t\"\n"
+	expected := "# Database\nDATABASE_URL=<postgres-url> # primary\nPORT=<number>\nDEBUG=<true|false>\nEMPTY=\nTOKEN=<string>\n"
+
+	example := string(Example(Parse([]byte(input))))
+	if example != expected {
+		t.Errorf("Example() = %q, want %q", example, expected)
+	}
+	if strings.Contains(example, "s3cr3t") {
+		t.Error("Example() leaks a value")
+	}
+}
+
+func TestKeyDiff(t *testing.T) {
+	onlyA, onlyB := KeyDiff([]string{"A", "B", "C"}, []string{"C", "D", "A"})
+	if strings.Join(onlyA, ",") != "B" || strings.Join(onlyB, ",") != "D" {
+		t.Errorf("KeyDiff() = %v, %v, want [B], [D]", onlyA, onlyB)
+	}
+}
+
+func TestSet(t *testing.T) {
+	file := Parse([]byte("# keep\nexport FOO=old # note\nBAR=x\n"))
+	file.Set("FOO", "new value")
+	file.Set("NEW", "line1\nline2")
+
+	expected := "# keep\nexport FOO=\"new value\" # note\nBAR=x\nNEW=\"line1\\nline2\"\n"
+	if got := string(file.Bytes()); got != expected {
+		t.Errorf("Bytes() = %q, want %q", got, expected)
+	}
+
+	reparsed := Parse(file.Bytes())
+	if value, _ := reparsed.Get("NEW"); value != "line1\nline2" {
+		t.Errorf("Get(NEW) = %q after round trip", value)
+	}
+}
+
+func TestMetaDeadline(t *testing.T) {
+	rotated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		meta     Meta
+		expected time.Time
+		found    bool
+	}{
+		{name: "no deadline", meta: Meta{Key: "A", RotatedAt: &rotated}, found: false},
+		{name: "interval", meta: Meta{Key: "A", RotatedAt: &rotated, RotateEvery: "10d"}, expected: rotated.AddDate(0, 0, 10), found: true},
+		{name: "interval from creation", meta: Meta{Key: "A", CreatedAt: &rotated, RotateEvery: "2w"}, expected: rotated.AddDate(0, 0, 14), found: true},
+		{name: "expiry", meta: Meta{Key: "A", ExpiresAt: &expires}, expected: expires, found: true},
+		{name: "earlier of both", meta: Meta{Key: "A", RotatedAt: &rotated, RotateEvery: "90d", ExpiresAt: &expires}, expected: expires, found: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadline, found := tt.meta.Deadline()
+			if found != tt.found || !deadline.Equal(tt.expected) {
+				t.Errorf("Deadline() = %v, %v, want %v, %v", deadline, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+func TestSetMeta(t *testing.T) {
+	file := Parse([]byte("A=1\nB=2\n"))
+	if err := file.SetMeta(&Meta{Key: "B", Owner: "ops"}); err != nil {
+		t.Fatalf("SetMeta() error = %v", err)
+	}
+	if err := file.SetMeta(&Meta{Key: "B", Owner: "payments"}); err != nil {
+		t.Fatalf("SetMeta() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(file.Bytes())), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], metaPrefix) || lines[2] != "B=2" {
+		t.Fatalf("unexpected layout: %q", lines)
+	}
+
+	meta, ok := Parse(file.Bytes()).Meta("B")
+	if !ok || meta.Owner != "payments" {
+		t.Errorf("Meta(B) = %+v, %v, want owner payments", meta, ok)
+	}
+	if _, ok := Parse(file.Bytes()).Get("B"); !ok {
+		t.Error("metadata line hides the key")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := Parse([]byte("A=1\nB=2\nC=3\n"))
+	new := Parse([]byte("A=1\nB=changed\nD=4\n"))
+
+	expected := []Change{
+		{Key: "B", Kind: Changed, Old: "2", New: "changed"},
+		{Key: "C", Kind: Removed, Old: "3"},
+		{Key: "D", Kind: Added, New: "4"},
+	}
+
+	changes := Diff(old, new)
+	if len(changes) != len(expected) {
+		t.Fatalf("Diff() = %+v, want %+v", changes, expected)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], expected[i])
+		}
+	}
+
+	if changes := Diff(old, old); len(changes) != 0 {
+		t.Errorf("Diff() of identical files = %+v, want none", changes)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := Parse([]byte("# app\nA=1\nB=1\nC=1\nD=1\n"))
+	ours := Parse([]byte("# app\nA=2\nB=1\nC=ours\nD=1\nE=new\n"))
+	theirs := Parse([]byte("A=1\nB=2\nC=theirs\nF=added\n"))
+
+	merged, conflicts := Merge(base, ours, theirs)
+
+	if len(conflicts) != 1 || conflicts[0] != "C" {
+		t.Errorf("conflicts = %v, want [C]", conflicts)
+	}
+	if !merged.HasConflicts() {
+		t.Error("HasConflicts() = false, want true")
+	}
+	if _, ok := Parse(merged.Bytes()).Map()[""]; ok {
+		t.Error("conflict separator parsed as an assignment")
+	}
+
+	expected := "# app\nA=2\nB=2\n" + ConflictOurs + "\nC=ours\n" + ConflictSep + "\nC=theirs\n" + ConflictTheirs + "\nE=new\nF=added\n"
+	if got := string(merged.Bytes()); got != expected {
+		t.Errorf("Merge() =\n%s\nwant\n%s", got, expected)
+	}
+
+	clean, conflicts := Merge(base, ours, base)
+	if len(conflicts) != 0 || clean.HasConflicts() || string(clean.Bytes()) != string(ours.Bytes()) {
+		t.Errorf("Merge() with unchanged theirs = %q, %v", clean.Bytes(), conflicts)
+	}
+}
@@ -0,0 +1,106 @@
+package dotenv
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Example renders a .env.example for the file: comments and blank lines are
+// kept, every key appears once, and each value is replaced by a placeholder
+// describing its shape rather than its content. Key descriptions from the
+// metadata become comments; the rest of the metadata is left out.
+func Example(file *File) []byte {
+	var b strings.Builder
+	seen := make(map[string]bool)
+	values := file.Map()
+	meta := file.AllMeta()
+
+	for _, line := range file.Lines {
+		switch line.Kind {
+		case Blank:
+			b.WriteString("\n")
+		case Comment:
+			if line.IsMeta() {
+				continue
+			}
+			b.WriteString(strings.TrimSpace(strings.TrimSuffix(line.Raw, "\r")))
+			b.WriteString("\n")
+		case Assignment:
+			if seen[line.Key] {
+				continue
+			}
+			seen[line.Key] = true
+
+			if m, ok := meta[line.Key]; ok && m.Description != "" {
+				b.WriteString("# " + m.Description + "\n")
+			}
+			if line.Export {
+				b.WriteString("export ")
+			}
+			b.WriteString(line.Key)
+			b.WriteString("=")
+			b.WriteString(Placeholder(values[line.Key]))
+			if line.Comment != "" {
+				b.WriteString(" ")
+				b.WriteString(line.Comment)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// Placeholder describes a value without revealing it
+func Placeholder(value string) string {
+	switch {
+	case value == "":
+		return ""
+	case value == "true" || value == "false":
+		return "<true|false>"
+	case isNumber(value):
+		return "<number>"
+	case strings.Contains(value, "\n"):
+		return "<multiline>"
+	}
+
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		return "<" + u.Scheme + "-url>"
+	}
+
+	return "<string>"
+}
+
+// KeyDiff compares two key sets and returns the keys only in a and only in b
+func KeyDiff(a, b []string) (onlyA, onlyB []string) {
+	inA := make(map[string]bool)
+	inB := make(map[string]bool)
+	for _, key := range a {
+		inA[key] = true
+	}
+	for _, key := range b {
+		inB[key] = true
+	}
+
+	for key := range inA {
+		if !inB[key] {
+			onlyA = append(onlyA, key)
+		}
+	}
+	for key := range inB {
+		if !inA[key] {
+			onlyB = append(onlyB, key)
+		}
+	}
+
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return onlyA, onlyB
+}
+
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
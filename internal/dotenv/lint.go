@@ -0,0 +1,261 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue codes reported by Lint
+const (
+	CodeDuplicateKey  = "duplicate-key"
+	CodeInvalidName   = "invalid-name"
+	CodeWhitespace    = "whitespace"
+	CodeUnquotedSpace = "unquoted-space"
+	CodeMixedExport   = "mixed-export"
+	CodeCRLF          = "crlf"
+	CodeDroppedByRun  = "dropped-by-run"
+)
+
+// Issue is a single lint finding. Messages never include values.
+type Issue struct {
+	Line     int    `json:"line"`
+	Key      string `json:"key,omitempty"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Fixable  bool   `json:"fixable"`
+}
+
+// Lint checks .env data for problems that cause surprises at run time
+func Lint(data []byte) []Issue {
+	file := Parse(data)
+	var issues []Issue
+
+	crlfLines := 0
+	firstCRLF := 0
+	for i, physical := range strings.Split(string(data), "\n") {
+		if strings.HasSuffix(physical, "\r") {
+			if crlfLines == 0 {
+				firstCRLF = i + 1
+			}
+			crlfLines++
+		}
+	}
+	if crlfLines > 0 {
+		issues = append(issues, Issue{
+			Line:     firstCRLF,
+			Code:     CodeCRLF,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("file uses CRLF line endings (%d lines)", crlfLines),
+			Fixable:  true,
+		})
+	}
+
+	hasExport, hasPlain := false, false
+	firstSeen := make(map[string]int)
+
+	for _, line := range file.Lines {
+		switch line.Kind {
+		case Blank, Comment:
+			if text := strings.TrimSuffix(line.Raw, "\r"); text != strings.TrimSpace(text) {
+				issues = append(issues, Issue{
+					Line:     line.Number,
+					Code:     CodeWhitespace,
+					Severity: SeverityWarning,
+					Message:  "line has leading or trailing whitespace",
+					Fixable:  true,
+				})
+			}
+			continue
+
+		case Invalid:
+			issues = append(issues, Issue{
+				Line:     line.Number,
+				Code:     CodeDroppedByRun,
+				Severity: SeverityError,
+				Message:  "line is not KEY=VALUE; `run` ignores it",
+			})
+			continue
+		}
+
+		if line.Export {
+			hasExport = true
+		} else {
+			hasPlain = true
+		}
+
+		if !ValidKey(line.Key) {
+			issues = append(issues, Issue{
+				Line:     line.Number,
+				Key:      line.Key,
+				Code:     CodeInvalidName,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("%q is not a valid variable name (use letters, digits and _, not starting with a digit)", line.Key),
+			})
+		}
+
+		if first, ok := firstSeen[line.Key]; ok {
+			issues = append(issues, Issue{
+				Line:     line.Number,
+				Key:      line.Key,
+				Code:     CodeDuplicateKey,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("%s is already defined on line %d; the last definition wins", line.Key, first),
+			})
+		} else {
+			firstSeen[line.Key] = line.Number
+		}
+
+		if hasOuterWhitespace(line) {
+			issues = append(issues, Issue{
+				Line:     line.Number,
+				Key:      line.Key,
+				Code:     CodeWhitespace,
+				Severity: SeverityWarning,
+				Message:  "line has leading or trailing whitespace",
+				Fixable:  true,
+			})
+		}
+
+		if line.Quote == 0 && strings.ContainsAny(line.Value, " \t") {
+			issues = append(issues, Issue{
+				Line:     line.Number,
+				Key:      line.Key,
+				Code:     CodeUnquotedSpace,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("value of %s contains spaces but is not quoted", line.Key),
+				Fixable:  true,
+			})
+		}
+
+		for _, reason := range droppedByRun(line) {
+			issues = append(issues, Issue{
+				Line:     line.Number,
+				Key:      line.Key,
+				Code:     CodeDroppedByRun,
+				Severity: SeverityError,
+				Message:  reason,
+				Fixable:  reason == spaceAroundEquals,
+			})
+		}
+	}
+
+	if hasExport && hasPlain {
+		for _, line := range file.Entries() {
+			if line.Export {
+				issues = append(issues, Issue{
+					Line:     line.Number,
+					Key:      line.Key,
+					Code:     CodeMixedExport,
+					Severity: SeverityWarning,
+					Message:  "file mixes `export KEY=...` lines with plain KEY=... lines",
+				})
+				break
+			}
+		}
+	}
+
+	return issues
+}
+
+const spaceAroundEquals = "whitespace before '=' becomes part of the name under `run`, so the key is never set"
+
+// droppedByRun explains why the current `run` parser would lose this entry.
+// `run` keeps each trimmed line containing '=' verbatim, so anything that
+// is not a plain single-line KEY=VALUE never reaches the child as intended.
+func droppedByRun(line Line) []string {
+	var reasons []string
+	if line.Export {
+		reasons = append(reasons, fmt.Sprintf("`run` passes this as \"export %s\", so %s is never set", line.Key, line.Key))
+	}
+	if line.Multiline() {
+		reasons = append(reasons, "`run` only reads the first line of a multi-line value")
+	}
+	if !line.Export && !hasPlainPrefix(line) {
+		reasons = append(reasons, spaceAroundEquals)
+	}
+	return reasons
+}
+
+// hasPlainPrefix reports whether the key is written directly against '='
+func hasPlainPrefix(line Line) bool {
+	text := strings.TrimSpace(strings.TrimSuffix(line.Raw, "\r"))
+	if line.Export {
+		text, _ = cutExport(text)
+	}
+	return strings.HasPrefix(text, line.Key+"=")
+}
+
+func hasOuterWhitespace(line Line) bool {
+	if line.Multiline() {
+		first := strings.SplitN(line.Raw, "\n", 2)[0]
+		return first != strings.TrimLeft(first, " \t")
+	}
+	text := strings.TrimSuffix(line.Raw, "\r")
+	return text != strings.TrimSpace(text)
+}
+
+// Fix rewrites the safe-to-fix problems reported by Lint: CRLF endings,
+// stray whitespace and unquoted values containing spaces. It returns the
+// new content and the number of lines that changed.
+func Fix(data []byte) ([]byte, int) {
+	file := Parse(data)
+	var b strings.Builder
+	changed := 0
+
+	for _, line := range file.Lines {
+		fixed := fixLine(line)
+		if fixed != line.Raw {
+			changed++
+		}
+		b.WriteString(fixed)
+		b.WriteByte('\n')
+	}
+
+	return []byte(b.String()), changed
+}
+
+func fixLine(line Line) string {
+	raw := strings.ReplaceAll(line.Raw, "\r\n", "\n")
+	raw = strings.TrimSuffix(raw, "\r")
+
+	switch line.Kind {
+	case Blank:
+		return ""
+	case Comment, Invalid:
+		return strings.TrimSpace(raw)
+	}
+
+	if line.Multiline() {
+		return strings.TrimLeft(raw, " \t")
+	}
+
+	unquotedSpace := line.Quote == 0 && strings.ContainsAny(line.Value, " \t")
+	if !unquotedSpace && !hasOuterWhitespace(line) && hasPlainPrefix(line) {
+		return raw
+	}
+
+	value := line.RawValue
+	if unquotedSpace {
+		value = `"` + escape(line.Value) + `"`
+	}
+
+	var b strings.Builder
+	if line.Export {
+		b.WriteString("export ")
+	}
+	b.WriteString(line.Key)
+	b.WriteByte('=')
+	b.WriteString(value)
+	if line.Comment != "" {
+		b.WriteByte(' ')
+		b.WriteString(line.Comment)
+	}
+	return b.String()
+}
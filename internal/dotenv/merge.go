@@ -0,0 +1,151 @@
+package dotenv
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Conflict markers written by Merge, in the same shape git uses
+const (
+	ConflictOurs   = "<<<<<<< ours"
+	ConflictSep    = "======="
+	ConflictTheirs = ">>>>>>> theirs"
+)
+
+// ErrConflicts is returned for a file that still has conflict markers.
+// Keys inside a conflict block parse as theirs, so such a file must not
+// be used until it is resolved.
+var ErrConflicts = errors.New("unresolved merge conflicts")
+
+// Merge performs a key-wise three-way merge. The result starts from ours,
+// so comments and ordering follow our side. A key changed on only one side
+// takes that side's value (including deletion); a key changed differently
+// on both sides is written as a conflict block and returned in conflicts.
+// Metadata is merged the same way, except that a metadata conflict keeps
+// ours, since it is never worth stopping a merge for.
+func Merge(base, ours, theirs *File) (merged *File, conflicts []string) {
+	merged = &File{Lines: append([]Line(nil), ours.Lines...)}
+
+	baseValues, ourValues, theirValues := base.Map(), ours.Map(), theirs.Map()
+	baseMeta, ourMeta, theirMeta := base.AllMeta(), ours.AllMeta(), theirs.AllMeta()
+
+	for _, key := range unionKeys(baseValues, ourValues, theirValues) {
+		b, inBase := baseValues[key]
+		o, inOurs := ourValues[key]
+		t, inTheirs := theirValues[key]
+
+		switch {
+		case inOurs == inTheirs && o == t:
+			// Both sides agree
+		case inOurs == inBase && o == b:
+			// Only theirs changed
+			if inTheirs {
+				merged.Set(key, t)
+			} else {
+				merged.Unset(key)
+				continue
+			}
+		case inTheirs == inBase && t == b:
+			// Only ours changed
+		default:
+			merged.conflict(key, o, inOurs, t, inTheirs)
+			conflicts = append(conflicts, key)
+			continue
+		}
+
+		// Take their metadata when only their side changed it
+		if _, ok := merged.Get(key); ok && theirMeta[key] != nil &&
+			sameMeta(ourMeta[key], baseMeta[key]) && !sameMeta(theirMeta[key], baseMeta[key]) {
+			merged.SetMeta(theirMeta[key])
+		}
+	}
+
+	return merged, conflicts
+}
+
+// Unset removes every definition of key and its metadata
+func (f *File) Unset(key string) {
+	lines := f.Lines[:0:0]
+	for _, line := range f.Lines {
+		if line.Kind == Assignment && line.Key == key {
+			continue
+		}
+		if m, ok := line.meta(); ok && m.Key == key {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	f.Lines = lines
+}
+
+// HasConflicts reports whether the file still contains conflict markers
+func (f *File) HasConflicts() bool {
+	for _, line := range f.Lines {
+		if isConflictMarker(strings.TrimSpace(line.Raw)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isConflictMarker reports whether a trimmed line is a conflict marker
+func isConflictMarker(text string) bool {
+	return text == ConflictSep || strings.HasPrefix(text, ConflictOurs[:7]) || strings.HasPrefix(text, ConflictTheirs[:7])
+}
+
+// conflict replaces the last definition of key with a conflict block, or
+// appends the block when our side deleted the key
+func (f *File) conflict(key, ours string, inOurs bool, theirs string, inTheirs bool) {
+	side := func(value string, present bool) string {
+		if !present {
+			return "# " + key + " deleted"
+		}
+		return FormatLine(key, value)
+	}
+	block := Parse([]byte(strings.Join([]string{
+		ConflictOurs,
+		side(ours, inOurs),
+		ConflictSep,
+		side(theirs, inTheirs),
+		ConflictTheirs,
+	}, "\n"))).Lines
+
+	at := -1
+	for i := len(f.Lines) - 1; i >= 0; i-- {
+		if f.Lines[i].Kind == Assignment && f.Lines[i].Key == key {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		f.Lines = append(f.Lines, block...)
+		return
+	}
+
+	lines := append([]Line(nil), f.Lines[:at]...)
+	lines = append(lines, block...)
+	f.Lines = append(lines, f.Lines[at+1:]...)
+}
+
+func unionKeys(sets ...map[string]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, set := range sets {
+		for key := range set {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sameMeta(a, b *Meta) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
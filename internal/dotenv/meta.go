@@ -0,0 +1,152 @@
+package dotenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metaPrefix marks a comment line that carries metadata for one key. The
+// metadata lives inside the bundle plaintext, so it is encrypted along
+// with the values and ignored by anything that treats it as a comment.
+const metaPrefix = "# secretsnap:meta "
+
+// Meta is optional per-key metadata
+type Meta struct {
+	Key         string     `json:"key"`
+	Owner       string     `json:"owner,omitempty"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	RotatedAt   *time.Time `json:"rotated_at,omitempty"`
+	RotateEvery string     `json:"rotate_every,omitempty"` // e.g. "90d", "12w", "720h"
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// Deadline returns when the key must next be rotated: the earlier of its
+// expiry date and its last rotation plus the rotation interval
+func (m *Meta) Deadline() (time.Time, bool) {
+	var deadline time.Time
+	found := false
+
+	if m.ExpiresAt != nil {
+		deadline = *m.ExpiresAt
+		found = true
+	}
+
+	if m.RotateEvery != "" {
+		interval, err := ParseInterval(m.RotateEvery)
+		last := m.RotatedAt
+		if last == nil {
+			last = m.CreatedAt
+		}
+		if err == nil && last != nil {
+			due := last.Add(interval)
+			if !found || due.Before(deadline) {
+				deadline = due
+			}
+			found = true
+		}
+	}
+
+	return deadline, found
+}
+
+// Overdue reports whether the deadline has passed at now
+func (m *Meta) Overdue(now time.Time) bool {
+	deadline, ok := m.Deadline()
+	return ok && now.After(deadline)
+}
+
+// ParseInterval parses a rotation interval. Besides Go durations it
+// accepts whole days ("90d") and weeks ("12w").
+func ParseInterval(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, suffix)); err == nil && strings.HasSuffix(s, suffix) && n > 0 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid interval '%s' (use e.g. 90d, 12w or 720h)", s)
+	}
+	return d, nil
+}
+
+// ParseDate parses an expiry given as YYYY-MM-DD or RFC 3339
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s' (use YYYY-MM-DD or RFC 3339)", s)
+	}
+	return t, nil
+}
+
+// IsMeta reports whether a line carries key metadata
+func (l Line) IsMeta() bool {
+	return l.Kind == Comment && strings.HasPrefix(strings.TrimSpace(l.Raw), metaPrefix)
+}
+
+func (l Line) meta() (*Meta, bool) {
+	if !l.IsMeta() {
+		return nil, false
+	}
+	var m Meta
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(l.Raw), metaPrefix)), &m); err != nil || m.Key == "" {
+		return nil, false
+	}
+	return &m, true
+}
+
+// Meta returns the metadata recorded for key
+func (f *File) Meta(key string) (*Meta, bool) {
+	m, ok := f.AllMeta()[key]
+	return m, ok
+}
+
+// AllMeta returns the metadata of every key that has some
+func (f *File) AllMeta() map[string]*Meta {
+	all := make(map[string]*Meta)
+	for _, line := range f.Lines {
+		if m, ok := line.meta(); ok {
+			all[m.Key] = m
+		}
+	}
+	return all
+}
+
+// SetMeta records metadata for m.Key. An existing metadata line is
+// rewritten in place; otherwise the line is inserted above the key.
+func (f *File) SetMeta(m *Meta) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	raw := metaPrefix + string(data)
+
+	for i, line := range f.Lines {
+		if existing, ok := line.meta(); ok && existing.Key == m.Key {
+			f.Lines[i] = parseLine(raw, line.Number)
+			return nil
+		}
+	}
+
+	at := len(f.Lines)
+	for i := len(f.Lines) - 1; i >= 0; i-- {
+		if f.Lines[i].Kind == Assignment && f.Lines[i].Key == m.Key {
+			at = i
+			break
+		}
+	}
+
+	f.Lines = append(f.Lines, Line{})
+	copy(f.Lines[at+1:], f.Lines[at:])
+	f.Lines[at] = parseLine(raw, at+1)
+	return nil
+}
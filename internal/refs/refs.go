@@ -0,0 +1,133 @@
+package refs
+
+import (
+	"fmt"
+	"strings"
+
+	"secretsnap/internal/dotenv"
+)
+
+const (
+	// ProjectScheme prefixes references to another project's environment:
+	// secretsnap://<project>/<env>/<KEY>
+	ProjectScheme = "secretsnap://"
+	// PathScheme prefixes references to a bundle file: ref:<path>#<KEY>
+	PathScheme = "ref:"
+)
+
+// Ref points at a key in another bundle. Either Project and Env or Path is
+// set, depending on the form it was written in.
+type Ref struct {
+	Project string
+	Env     string
+	Path    string
+	Key     string
+}
+
+// String renders the reference the way it was written
+func (r *Ref) String() string {
+	if r.Path != "" {
+		return PathScheme + r.Path + "#" + r.Key
+	}
+	return ProjectScheme + r.Project + "/" + r.Env + "/" + r.Key
+}
+
+// Parse reports whether value is a well-formed reference and parses it.
+// Values that only share a prefix with the reference forms, such as
+// GIT_REF=ref:main, are not references and are left alone.
+func Parse(value string) (*Ref, bool) {
+	switch {
+	case strings.HasPrefix(value, ProjectScheme):
+		parts := strings.Split(strings.TrimPrefix(value, ProjectScheme), "/")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || !dotenv.ValidKey(parts[2]) {
+			return nil, false
+		}
+		return &Ref{Project: parts[0], Env: parts[1], Key: parts[2]}, true
+	case strings.HasPrefix(value, PathScheme):
+		rest := strings.TrimPrefix(value, PathScheme)
+		idx := strings.LastIndex(rest, "#")
+		if idx <= 0 || !dotenv.ValidKey(rest[idx+1:]) {
+			return nil, false
+		}
+		return &Ref{Path: rest[:idx], Key: rest[idx+1:]}, true
+	}
+	return nil, false
+}
+
+// Location identifies a bundle and the project whose cached key opens it
+type Location struct {
+	Path    string // cleaned absolute path, used to detect cycles
+	Project string
+}
+
+// Resolver replaces references with the values they point at. Locate maps
+// a reference made from the bundle at from to the bundle it names, and Open
+// decrypts and parses a bundle. Each bundle is opened at most once.
+type Resolver struct {
+	Locate func(ref *Ref, from Location) (Location, error)
+	Open   func(loc Location) (*dotenv.File, error)
+
+	files map[string]*dotenv.File
+}
+
+// Resolve replaces every reference in file, which was read from loc, with
+// the value it points at. References may point at further references; a
+// chain that returns to a key it already visited is reported as a cycle.
+func (r *Resolver) Resolve(file *dotenv.File, loc Location) error {
+	if r.files == nil {
+		r.files = make(map[string]*dotenv.File)
+	}
+	r.files[loc.Path] = file
+
+	values := file.Map()
+	for _, key := range file.Keys() {
+		ref, ok := Parse(values[key])
+		if !ok {
+			continue
+		}
+
+		value, err := r.follow(ref, loc, []string{loc.Path + "#" + key})
+		if err != nil {
+			return fmt.Errorf("cannot resolve %s=%s: %w", key, ref, err)
+		}
+		file.Set(key, value)
+	}
+
+	return nil
+}
+
+// follow resolves ref and any references it leads to. visited holds the
+// chain of bundle#KEY entries seen so far.
+func (r *Resolver) follow(ref *Ref, from Location, visited []string) (string, error) {
+	loc, err := r.Locate(ref, from)
+	if err != nil {
+		return "", err
+	}
+
+	id := loc.Path + "#" + ref.Key
+	for _, seen := range visited {
+		if seen == id {
+			return "", fmt.Errorf("reference cycle: %s", strings.Join(append(visited, id), " -> "))
+		}
+	}
+
+	file, ok := r.files[loc.Path]
+	if !ok {
+		file, err = r.Open(loc)
+		if err != nil {
+			return "", err
+		}
+		r.files[loc.Path] = file
+	}
+
+	value, ok := file.Get(ref.Key)
+	if !ok {
+		return "", fmt.Errorf("%s is not set in %s", ref.Key, loc.Path)
+	}
+
+	next, ok := Parse(value)
+	if !ok {
+		return value, nil
+	}
+	return r.follow(next, loc, append(visited, id))
+}
@@ -0,0 +1,98 @@
+package refs
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+
+	"secretsnap/internal/dotenv"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  *Ref
+		isRef bool
+	}{
+		{value: "plain", isRef: false},
+		{value: "secretsnap://shared/prod/STRIPE_KEY", want: &Ref{Project: "shared", Env: "prod", Key: "STRIPE_KEY"}, isRef: true},
+		{value: "ref:../shared/secrets.envsnap#STRIPE_KEY", want: &Ref{Path: "../shared/secrets.envsnap", Key: "STRIPE_KEY"}, isRef: true},
+		{value: "secretsnap://shared/STRIPE_KEY", isRef: false},
+		{value: "ref:secrets.envsnap", isRef: false},
+		{value: "ref:#KEY", isRef: false},
+		{value: "ref:main", isRef: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, isRef := Parse(tt.value)
+			if isRef != tt.isRef {
+				t.Fatalf("Parse() = %v, %v", got, isRef)
+			}
+			if tt.want != nil && *got != *tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	bundles := map[string]string{
+		"/app/secrets.envsnap":    "A=ref:../shared/secrets.envsnap#STRIPE_KEY\nB=secretsnap://shared/prod/DB_URL\nC=plain\n",
+		"/shared/secrets.envsnap": "STRIPE_KEY=sk_live\n",
+		"/shared/prod.envsnap":    "DB_URL=ref:secrets.envsnap#STRIPE_KEY\n",
+		"/loop/a.envsnap":         "X=ref:b.envsnap#Y\n",
+		"/loop/b.envsnap":         "Y=ref:a.envsnap#X\n",
+		"/missing/a.envsnap":      "X=ref:../shared/secrets.envsnap#NOPE\n",
+	}
+
+	newResolver := func() *Resolver {
+		return &Resolver{
+			Locate: func(ref *Ref, from Location) (Location, error) {
+				if ref.Path != "" {
+					return Location{Path: path.Join(path.Dir(from.Path), ref.Path)}, nil
+				}
+				return Location{Path: "/" + ref.Project + "/" + ref.Env + ".envsnap"}, nil
+			},
+			Open: func(loc Location) (*dotenv.File, error) {
+				data, ok := bundles[loc.Path]
+				if !ok {
+					return nil, fmt.Errorf("no bundle at %s", loc.Path)
+				}
+				return dotenv.Parse([]byte(data)), nil
+			},
+		}
+	}
+
+	tests := []struct {
+		bundle  string
+		want    map[string]string
+		wantErr string
+	}{
+		{bundle: "/app/secrets.envsnap", want: map[string]string{"A": "sk_live", "B": "sk_live", "C": "plain"}},
+		{bundle: "/loop/a.envsnap", wantErr: "reference cycle"},
+		{bundle: "/missing/a.envsnap", wantErr: "NOPE is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bundle, func(t *testing.T) {
+			file := dotenv.Parse([]byte(bundles[tt.bundle]))
+			err := newResolver().Resolve(file, Location{Path: tt.bundle})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			for key, want := range tt.want {
+				if got, _ := file.Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
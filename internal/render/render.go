@@ -0,0 +1,56 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"text/template"
+)
+
+// Funcs returns the template helpers for values:
+//
+//	env "KEY"          the value of KEY, or "" when it is not set
+//	required "KEY"     the value of KEY, failing when it is missing or empty
+//	default "x" VALUE  VALUE, or "x" when VALUE is empty
+//	b64enc VALUE       standard base64 encoding of VALUE
+//	quote VALUE        VALUE as a double-quoted, escaped string
+func Funcs(values map[string]string) template.FuncMap {
+	return template.FuncMap{
+		"env": func(key string) string {
+			return values[key]
+		},
+		"required": func(key string) (string, error) {
+			if values[key] == "" {
+				return "", fmt.Errorf("required key %s is not set", key)
+			}
+			return values[key], nil
+		},
+		"default": func(fallback, value string) string {
+			if value == "" {
+				return fallback
+			}
+			return value
+		},
+		"b64enc": func(value string) string {
+			return base64.StdEncoding.EncodeToString([]byte(value))
+		},
+		"quote": strconv.Quote,
+	}
+}
+
+// Render executes a text/template with values as its data, so keys can be
+// used directly as {{ .KEY }}. Referencing a key that is not set is an error.
+func Render(name, text string, values map[string]string) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(Funcs(values)).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, values); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	values := map[string]string{
+		"DB_URL": "postgres://db/app",
+		"TOKEN":  `a"b`,
+		"EMPTY":  "",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+		wantErr  string
+	}{
+		{name: "field", template: "url: {{ .DB_URL }}", expected: "url: postgres://db/app"},
+		{name: "env", template: `{{ env "DB_URL" }}|{{ env "NOPE" }}`, expected: "postgres://db/app|"},
+		{name: "default", template: `{{ env "PORT" | default "8080" }}`, expected: "8080"},
+		{name: "quote", template: `{{ .TOKEN | quote }}`, expected: `"a\"b"`},
+		{name: "b64enc", template: `{{ b64enc .DB_URL }}`, expected: "cG9zdGdyZXM6Ly9kYi9hcHA="},
+		{name: "required", template: `{{ required "DB_URL" }}`, expected: "postgres://db/app"},
+		{name: "required missing", template: `{{ required "EMPTY" }}`, wantErr: "required key EMPTY is not set"},
+		{name: "missing field", template: `{{ .NOPE }}`, wantErr: "NOPE"},
+		{name: "bad syntax", template: `{{ .DB_URL `, wantErr: "failed to parse template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Render(tt.name, tt.template, values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Render() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("Render() = %q, want %q", out, tt.expected)
+			}
+		})
+	}
+}
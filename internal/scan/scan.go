@@ -0,0 +1,218 @@
+package scan
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"secretsnap/internal/crypto"
+)
+
+// MinValueLength is the shortest value a Matcher looks for. Shorter values
+// such as "true" or "3000" would match all over a codebase.
+const MinValueLength = 8
+
+// Match is a secret value found in some content
+type Match struct {
+	Key      string `json:"key"`
+	Line     int    `json:"line"`
+	Encoding string `json:"encoding"` // "plain", "base64", "url" or "json"
+}
+
+// Matcher finds secret values, and their common encodings, in content
+// without keeping the values: it holds only keyed hashes of them. Windows of
+// each value's length slide over the content and are hashed the same way.
+// A rolling fingerprint of each window, truncated so it says next to nothing
+// about the value, picks the few windows worth hashing.
+type Matcher struct {
+	hasher  *crypto.ValueHasher
+	hashes  map[string]Match // hash -> key and encoding
+	windows []window         // shortest first
+	base    uint64           // random, so fingerprints differ per run
+}
+
+// window holds the fingerprints of the forms of one length
+type window struct {
+	length int
+	power  uint64          // base^length, to roll a byte out
+	prints map[uint32]bool // truncated fingerprints
+}
+
+// encodings are the forms a value is commonly leaked in besides plain text.
+// Base64 is matched without padding so both padded and raw forms are found.
+var encodings = []struct {
+	name   string
+	encode func(string) string
+}{
+	{"base64", func(v string) string { return base64.RawStdEncoding.EncodeToString([]byte(v)) }},
+	{"base64", func(v string) string { return base64.RawURLEncoding.EncodeToString([]byte(v)) }},
+	{"url", url.QueryEscape},
+	{"url", url.PathEscape},
+	{"json", func(v string) string {
+		data, _ := json.Marshal(v)
+		return string(data[1 : len(data)-1])
+	}},
+}
+
+// NewMatcher hashes values (key -> value), and their common encodings, with
+// a fresh random key. Values shorter than MinValueLength are ignored. The
+// matcher keeps no plaintext, so the caller can drop values once it is built.
+func NewMatcher(values map[string]string) (*Matcher, error) {
+	hasher, err := crypto.NewValueHasher()
+	if err != nil {
+		return nil, err
+	}
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate fingerprint base: %w", err)
+	}
+
+	m := &Matcher{hasher: hasher, hashes: make(map[string]Match), base: binary.LittleEndian.Uint64(seed[:]) | 1}
+	byLength := make(map[int]*window)
+	add := func(key, encoding, form string) {
+		hash := hasher.Hash(form)
+		if _, ok := m.hashes[hash]; ok {
+			return
+		}
+		m.hashes[hash] = Match{Key: key, Encoding: encoding}
+
+		w := byLength[len(form)]
+		if w == nil {
+			w = &window{length: len(form), power: m.pow(len(form)), prints: make(map[uint32]bool)}
+			byLength[len(form)] = w
+		}
+		w.prints[truncate(m.fingerprint([]byte(form)))] = true
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := values[key]
+		if len(value) < MinValueLength {
+			continue
+		}
+		add(key, "plain", value)
+		for _, encoding := range encodings {
+			add(key, encoding.name, encoding.encode(value))
+		}
+	}
+
+	for _, w := range byLength {
+		m.windows = append(m.windows, *w)
+	}
+	sort.Slice(m.windows, func(i, j int) bool {
+		return m.windows[i].length < m.windows[j].length
+	})
+
+	return m, nil
+}
+
+// Empty reports whether there is nothing to look for
+func (m *Matcher) Empty() bool {
+	return len(m.hashes) == 0
+}
+
+// Find returns every line of content that contains one of the values.
+// Each key is reported at most once per line.
+func (m *Matcher) Find(content []byte) []Match {
+	var matches []Match
+	if m.Empty() {
+		return matches
+	}
+
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		for _, match := range m.FindLine(line) {
+			match.Line = i + 1
+			matches = append(matches, match)
+		}
+	}
+
+	return matches
+}
+
+// FindLine returns the values found in a single line, each key at most
+// once. Line numbers are left for the caller to fill in.
+func (m *Matcher) FindLine(line []byte) []Match {
+	var matches []Match
+	found := make(map[string]bool)
+	for _, w := range m.windows {
+		if w.length > len(line) {
+			break
+		}
+		fp := m.fingerprint(line[:w.length])
+		for start := 0; ; start++ {
+			if w.prints[truncate(fp)] {
+				match, ok := m.hashes[m.hasher.Hash(string(line[start:start+w.length]))]
+				if ok && !found[match.Key] {
+					found[match.Key] = true
+					matches = append(matches, match)
+				}
+			}
+			if start+w.length == len(line) {
+				break
+			}
+			fp = fp*m.base + uint64(line[start+w.length]) - uint64(line[start])*w.power
+		}
+	}
+	return matches
+}
+
+// fingerprint is the polynomial rolling hash of data, modulo 2^64
+func (m *Matcher) fingerprint(data []byte) uint64 {
+	var fp uint64
+	for _, b := range data {
+		fp = fp*m.base + uint64(b)
+	}
+	return fp
+}
+
+// pow returns base^n, modulo 2^64
+func (m *Matcher) pow(n int) uint64 {
+	power := uint64(1)
+	for i := 0; i < n; i++ {
+		power *= m.base
+	}
+	return power
+}
+
+// truncate keeps the 20 best mixed bits of a fingerprint. A match is
+// confirmed by the keyed hash, so a false hit only costs one hash.
+func truncate(fp uint64) uint32 {
+	return uint32(fp >> 44)
+}
+
+// IsBinary reports whether content looks like a binary file
+func IsBinary(content []byte) bool {
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// IsDotenvName reports whether a file name looks like a plaintext dotenv
+// file: .env, .env.local, production.env and so on. Templates such as
+// .env.example are allowed.
+func IsDotenvName(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, suffix := range []string{".example", ".sample", ".template", ".dist"} {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	return name == ".env" || strings.HasPrefix(name, ".env.") || strings.HasSuffix(name, ".env")
+}
+
+// Describe formats a match for humans
+func (m Match) Describe(path string) string {
+	if m.Encoding != "plain" {
+		return fmt.Sprintf("%s:%d contains the value of %s (%s-encoded)", path, m.Line, m.Key, m.Encoding)
+	}
+	return fmt.Sprintf("%s:%d contains the value of %s", path, m.Line, m.Key)
+}
@@ -0,0 +1,115 @@
+package scan
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMatcherFind(t *testing.T) {
+	matcher, err := NewMatcher(map[string]string{
+		"STRIPE_KEY": "sk_live_abc123",
+		"DB_URL":     "postgres://user:pw@db/app",
+		"PORT":       "3000",
+	})
+	if err != nil {
+		t.Fatalf("NewMatcher() error = %v", err)
+	}
+
+	content := []byte("const port = 3000\nconst key = \"sk_live_abc123\"\n// postgres://user:pw@db/app and sk_live_abc123\n")
+	matches := matcher.Find(content)
+
+	expected := []Match{
+		{Key: "STRIPE_KEY", Line: 2, Encoding: "plain"},
+		{Key: "STRIPE_KEY", Line: 3, Encoding: "plain"},
+		{Key: "DB_URL", Line: 3, Encoding: "plain"},
+	}
+	if len(matches) != len(expected) {
+		t.Fatalf("Find() = %+v, want %+v", matches, expected)
+	}
+	for i := range expected {
+		if matches[i] != expected[i] {
+			t.Errorf("match %d = %+v, want %+v", i, matches[i], expected[i])
+		}
+	}
+}
+
+func TestMatcherEncodings(t *testing.T) {
+	matcher, err := NewMatcher(map[string]string{"TOKEN": `p@ss w/"quote"`})
+	if err != nil {
+		t.Fatalf("NewMatcher() error = %v", err)
+	}
+
+	tests := map[string]string{
+		`token: cEBzcyB3LyJxdW90ZSI=`:            "base64",
+		`https://x/?t=p%40ss+w%2F%22quote%22`:    "url",
+		`{"token": "p@ss w/\"quote\""}`:          "json",
+		`raw p@ss w/"quote" here`:                "plain",
+		`unrelated cEBzcyB3LyJxdW90ZSJ4 content`: "",
+	}
+
+	for content, expected := range tests {
+		matches := matcher.Find([]byte(content))
+		got := ""
+		if len(matches) > 0 {
+			got = matches[0].Encoding
+		}
+		if got != expected {
+			t.Errorf("Find(%q) encoding = %q, want %q", content, got, expected)
+		}
+	}
+}
+
+func TestMatcherKeepsNoPlaintext(t *testing.T) {
+	value := "sk_live_zyxwvutsrq"
+	matcher, err := NewMatcher(map[string]string{"STRIPE_KEY": value})
+	if err != nil {
+		t.Fatalf("NewMatcher() error = %v", err)
+	}
+
+	state := fmt.Sprintf("%#v %#v", *matcher, matcher.windows)
+	forms := []string{value, "c2tfbGl2ZV96eXh3dnV0c3Jx", "sk_live_zyx"}
+	for _, form := range forms {
+		if strings.Contains(state, form) {
+			t.Errorf("matcher holds %q", form)
+		}
+	}
+	if len(matcher.Find([]byte("key = "+value))) != 1 {
+		t.Errorf("Find() did not find the value")
+	}
+}
+
+func BenchmarkMatcherFind(b *testing.B) {
+	values := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		values[fmt.Sprintf("KEY_%d", i)] = fmt.Sprintf("secret-value-%d-abcdefghijkl", i)
+	}
+	matcher, err := NewMatcher(values)
+	if err != nil {
+		b.Fatalf("NewMatcher() error = %v", err)
+	}
+	content := []byte(strings.Repeat("an ordinary line of source code with nothing in it\n", 1600))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matcher.Find(content)
+	}
+}
+
+func TestIsDotenvName(t *testing.T) {
+	tests := map[string]bool{
+		".env":               true,
+		"app/.env.local":     true,
+		"production.env":     true,
+		".env.example":       false,
+		"config/.env.sample": false,
+		"environment.go":     false,
+		"secrets.envsnap":    false,
+	}
+
+	for path, expected := range tests {
+		if got := IsDotenvName(path); got != expected {
+			t.Errorf("IsDotenvName(%q) = %v, want %v", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package secretgen
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	alnumChars    = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	passwordChars = alnumChars + "!%&*+-.:=?@^_~" // nothing that needs quoting in a .env
+
+	defaultLength = 32
+	maxLength     = 4096
+)
+
+// Secret is a generated value. Keypair generators also fill in Public.
+type Secret struct {
+	Value  string
+	Public string
+}
+
+// Generators lists the supported specs for help text and errors
+var Generators = []string{
+	"password:N", "alnum:N", "hex:N", "base64:N", "uuid", "jwt-hs256", "rsa-2048", "ed25519",
+}
+
+// Generate creates a secret from a spec such as "password:32" or "uuid".
+// For password and alnum, N is the length in characters; for hex and
+// base64 it is the number of random bytes. N defaults to 32.
+func Generate(spec string) (*Secret, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+
+	length := defaultLength
+	if hasArg {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 || n > maxLength {
+			return nil, fmt.Errorf("invalid length '%s' in generator '%s' (1-%d)", arg, spec, maxLength)
+		}
+		length = n
+	}
+
+	switch name {
+	case "password":
+		return randomString(passwordChars, length)
+	case "alnum":
+		return randomString(alnumChars, length)
+	case "hex":
+		b, err := randomBytes(length)
+		if err != nil {
+			return nil, err
+		}
+		return &Secret{Value: hex.EncodeToString(b)}, nil
+	case "base64":
+		b, err := randomBytes(length)
+		if err != nil {
+			return nil, err
+		}
+		return &Secret{Value: base64.StdEncoding.EncodeToString(b)}, nil
+	}
+
+	if hasArg {
+		return nil, fmt.Errorf("generator '%s' does not take a length", name)
+	}
+
+	switch name {
+	case "uuid":
+		return generateUUID()
+	case "jwt-hs256":
+		// HS256 keys should be at least as long as the SHA-256 output
+		b, err := randomBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		return &Secret{Value: base64.RawURLEncoding.EncodeToString(b)}, nil
+	case "rsa-2048":
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		return encodeKeypair(key, &key.PublicKey)
+	case "ed25519":
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate ed25519 key: %w", err)
+		}
+		return encodeKeypair(private, public)
+	}
+
+	return nil, fmt.Errorf("unknown generator '%s'. Supported: %s", spec, strings.Join(Generators, ", "))
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return b, nil
+}
+
+// randomString picks each character uniformly from charset
+func randomString(charset string, length int) (*Secret, error) {
+	max := big.NewInt(int64(len(charset)))
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		b[i] = charset[n.Int64()]
+	}
+	return &Secret{Value: string(b)}, nil
+}
+
+// generateUUID creates a random (version 4) UUID
+func generateUUID() (*Secret, error) {
+	b, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return &Secret{Value: fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])}, nil
+}
+
+// encodeKeypair renders a private key as PKCS#8 PEM and its public key as PKIX PEM
+func encodeKeypair(private, public interface{}) (*Secret, error) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+
+	return &Secret{
+		Value:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		Public: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+	}, nil
+}
@@ -0,0 +1,66 @@
+package secretgen
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		check   func(s *Secret) bool
+		wantErr bool
+	}{
+		{name: "password", spec: "password:40", check: func(s *Secret) bool { return len(s.Value) == 40 }},
+		{name: "password default length", spec: "password", check: func(s *Secret) bool { return len(s.Value) == 32 }},
+		{name: "alnum", spec: "alnum:16", check: func(s *Secret) bool {
+			return regexp.MustCompile(`^[A-Za-z0-9]{16}$`).MatchString(s.Value)
+		}},
+		{name: "hex", spec: "hex:16", check: func(s *Secret) bool {
+			b, err := hex.DecodeString(s.Value)
+			return err == nil && len(b) == 16
+		}},
+		{name: "base64", spec: "base64:24", check: func(s *Secret) bool {
+			b, err := base64.StdEncoding.DecodeString(s.Value)
+			return err == nil && len(b) == 24
+		}},
+		{name: "uuid", spec: "uuid", check: func(s *Secret) bool {
+			return regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(s.Value)
+		}},
+		{name: "jwt-hs256", spec: "jwt-hs256", check: func(s *Secret) bool {
+			b, err := base64.RawURLEncoding.DecodeString(s.Value)
+			return err == nil && len(b) == 32
+		}},
+		{name: "ed25519", spec: "ed25519", check: func(s *Secret) bool {
+			return strings.Contains(s.Value, "BEGIN PRIVATE KEY") && strings.Contains(s.Public, "BEGIN PUBLIC KEY")
+		}},
+		{name: "unknown generator", spec: "nope", wantErr: true},
+		{name: "bad length", spec: "hex:abc", wantErr: true},
+		{name: "zero length", spec: "alnum:0", wantErr: true},
+		{name: "length on fixed generator", spec: "uuid:4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := Generate(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if err == nil && !tt.check(secret) {
+				t.Errorf("Generate(%q) produced an unexpected value shape", tt.spec)
+			}
+		})
+	}
+}
+
+func TestGenerateIsRandom(t *testing.T) {
+	a, _ := Generate("password:32")
+	b, _ := Generate("password:32")
+	if a.Value == b.Value {
+		t.Error("two generated passwords are identical")
+	}
+}
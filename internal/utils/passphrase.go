@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"secretsnap/internal/config"
+
+	"golang.org/x/term"
+)
+
+// PassphraseEnv is read when no passphrase flag is given
+const PassphraseEnv = "SECRETSNAP_PASSPHRASE"
+
+// PassphraseSource says where a passphrase comes from. At most one of
+// Pass, File, Stdin, FD and Cmd may be set; with none, the Env variable is
+// read, then the terminal is prompted.
+type PassphraseSource struct {
+	Pass  string // --pass, visible in ps and shell history
+	File  string // --pass-file
+	Stdin bool   // --pass-stdin
+	FD    int    // --pass-fd, 0 when unset
+	Cmd   string // --pass-cmd, run with the shell; its first line is used
+	Env   string // environment variable, "" to ignore the environment
+}
+
+// Explicit reports whether a flag names the source. Like --pass, any of
+// them selects passphrase mode; the environment variable does not.
+func (s PassphraseSource) Explicit() bool {
+	return s.Pass != "" || s.File != "" || s.Stdin || s.FD > 0 || s.Cmd != ""
+}
+
+// GetPassphrase retrieves the passphrase from its source, or prompts
+func GetPassphrase(src PassphraseSource) (string, error) {
+	if passphrase, ok, err := src.read(); ok || err != nil {
+		return passphrase, err
+	}
+	return promptPassphrase("Enter passphrase: ", src)
+}
+
+// GetNewPassphrase retrieves a passphrase for encrypting. A prompted
+// passphrase must be typed twice, and every passphrase must meet policy.
+func GetNewPassphrase(src PassphraseSource, policy *config.PassphrasePolicy) (string, error) {
+	if passphrase, ok, err := src.read(); ok || err != nil {
+		if err != nil {
+			return "", err
+		}
+		return passphrase, policy.Check(passphrase)
+	}
+
+	passphrase, err := promptPassphrase("Enter new passphrase: ", src)
+	if err != nil {
+		return "", err
+	}
+	if err := policy.Check(passphrase); err != nil {
+		return "", err
+	}
+	confirm, err := promptPassphrase("Confirm passphrase: ", src)
+	if err != nil {
+		return "", err
+	}
+	if confirm != passphrase {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// read returns the passphrase from a flag or the environment. ok is false
+// when neither is set and the user has to be prompted.
+func (s PassphraseSource) read() (passphrase string, ok bool, err error) {
+	sources := 0
+	for _, set := range []bool{s.Pass != "", s.File != "", s.Stdin, s.FD > 0, s.Cmd != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return "", true, fmt.Errorf("use only one of --pass, --pass-file, --pass-stdin, --pass-fd and --pass-cmd")
+	}
+
+	var data []byte
+	var what string
+	switch {
+	case s.Pass != "":
+		return s.Pass, true, nil
+	case s.File != "":
+		what = "passphrase file " + s.File
+		data, err = os.ReadFile(s.File)
+	case s.Stdin:
+		what = "stdin"
+		data, err = io.ReadAll(os.Stdin)
+	case s.FD > 0:
+		what = fmt.Sprintf("file descriptor %d", s.FD)
+		data, err = readFD(s.FD)
+	case s.Cmd != "":
+		what = "passphrase command"
+		data, err = runPassCmd(s.Cmd)
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[:i]
+		}
+	case s.Env != "" && os.Getenv(s.Env) != "":
+		return os.Getenv(s.Env), true, nil
+	default:
+		return "", false, nil
+	}
+	if err != nil {
+		return "", true, fmt.Errorf("failed to read passphrase from %s: %w", what, err)
+	}
+
+	passphrase = strings.TrimRight(string(data), "\r\n")
+	if passphrase == "" {
+		return "", true, fmt.Errorf("passphrase from %s is empty", what)
+	}
+	return passphrase, true, nil
+}
+
+// readFD reads everything from an inherited file descriptor
+func readFD(fd int) ([]byte, error) {
+	file := os.NewFile(uintptr(fd), fmt.Sprintf("fd %d", fd))
+	if file == nil {
+		return nil, fmt.Errorf("invalid file descriptor")
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// runPassCmd runs a password manager command with the shell and returns
+// its stdout. Its prompts (stderr) and input (stdin) stay on the terminal.
+func runPassCmd(command string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+// promptPassphrase reads a passphrase from the terminal without echoing it
+func promptPassphrase(prompt string, src PassphraseSource) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		hint := "--pass-stdin, --pass-file, --pass-fd or --pass-cmd"
+		if src.Env != "" {
+			hint += ", or set " + src.Env
+		}
+		return "", fmt.Errorf("no passphrase given and stdin is not a terminal. Use %s", hint)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("passphrase is empty")
+	}
+	return string(data), nil
+}
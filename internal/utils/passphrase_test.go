@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPassphraseSources(t *testing.T) {
+	passFile := filepath.Join(t.TempDir(), "pass.txt")
+	os.WriteFile(passFile, []byte("from file\n"), 0600)
+	t.Setenv("TEST_PASSPHRASE", "from env")
+
+	tests := []struct {
+		name    string
+		src     PassphraseSource
+		want    string
+		wantErr string
+	}{
+		{"flag", PassphraseSource{Pass: "from flag", Env: "TEST_PASSPHRASE"}, "from flag", ""},
+		{"file", PassphraseSource{File: passFile}, "from file", ""},
+		{"command first line", PassphraseSource{Cmd: "echo from command; echo user: me"}, "from command", ""},
+		{"env", PassphraseSource{Env: "TEST_PASSPHRASE"}, "from env", ""},
+		{"two sources", PassphraseSource{Pass: "a", File: passFile}, "", "only one of"},
+		{"empty command output", PassphraseSource{Cmd: "true"}, "", "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.src.Cmd != "" && runtime.GOOS == "windows" {
+				t.Skip("uses sh")
+			}
+			got, err := GetPassphrase(tt.src)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("GetPassphrase() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("GetPassphrase() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPassphraseEnvDoesNotSelectMode(t *testing.T) {
+	if (PassphraseSource{Env: PassphraseEnv}).Explicit() {
+		t.Errorf("Explicit() = true for an environment-only source")
+	}
+	if !(PassphraseSource{FD: 3}).Explicit() {
+		t.Errorf("Explicit() = false for --pass-fd")
+	}
+}
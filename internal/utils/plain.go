@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PlainWriter removes the emoji that prefix lines of human-readable output,
+// so `✅ Saved .env` becomes `Saved .env`. Only the start of each line
+// (after indentation) is touched; values later in a line are written as is.
+type PlainWriter struct {
+	W         io.Writer
+	afterLine bool // the last write did not end a line
+}
+
+// Write implements io.Writer
+func (p *PlainWriter) Write(data []byte) (int, error) {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		if p.afterLine {
+			b.WriteString(line)
+		} else {
+			b.WriteString(StripEmojiPrefix(line))
+		}
+		p.afterLine = !strings.HasSuffix(line, "\n")
+	}
+	if _, err := io.WriteString(p.W, b.String()); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// StripEmojiPrefix removes emoji (and the spaces after them) that follow
+// the indentation at the start of line
+func StripEmojiPrefix(line string) string {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	rest := line[indent:]
+
+	stripped := false
+	for rest != "" {
+		r, size := utf8.DecodeRuneInString(rest)
+		if !isEmoji(r) {
+			break
+		}
+		rest = strings.TrimLeftFunc(rest[size:], func(r rune) bool { return r == ' ' })
+		stripped = true
+	}
+	if !stripped {
+		return line
+	}
+	return line[:indent] + rest
+}
+
+// isEmoji reports whether r is a pictograph or emoji modifier
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, symbols
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // miscellaneous symbols, dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF, r >= 0x231A && r <= 0x23FF:
+		return true
+	case r == 0xFE0F, r == 0x200D, r == 0x2139:
+		return true
+	}
+	return unicode.Is(unicode.Variation_Selector, r)
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestStripEmojiPrefix(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"✅ Saved .env\n", "Saved .env\n"},
+		{"⚠️  Warning: mode 0644\n", "Warning: mode 0644\n"},
+		{"   💡 Run init\n", "   Run init\n"},
+		{"💡 🚀 Upgrade now\n", "Upgrade now\n"},
+		{"+ KEY=value 🎉\n", "+ KEY=value 🎉\n"},
+		{"plain text\n", "plain text\n"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := StripEmojiPrefix(tt.line); got != tt.want {
+			t.Errorf("StripEmojiPrefix(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestPlainWriterLineStarts(t *testing.T) {
+	var buf bytes.Buffer
+	w := &PlainWriter{W: &buf}
+
+	fmt.Fprint(w, "✅ one ")
+	fmt.Fprint(w, "🎉 same line\n📦 two\n")
+
+	if want := "one 🎉 same line\ntwo\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
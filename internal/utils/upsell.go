@@ -2,12 +2,21 @@ package utils
 
 import (
 	"fmt"
+	"io"
 	"math/rand"
+	"os"
 	"time"
 
 	"secretsnap/internal/config"
 )
 
+// UpsellOutput is where upsell banners are written. It is never stdout,
+// which only carries command data.
+var UpsellOutput io.Writer = os.Stderr
+
+// UpsellDisabled turns banners off (--quiet, CI, or the upsell setting)
+var UpsellDisabled bool
+
 // UpsellMessage represents a single upsell message with its category
 type UpsellMessage struct {
 	Message  string
@@ -83,30 +92,39 @@ var UpsellCallToAction = []string{
 
 // ShowUpsell displays a randomized upsell message if appropriate
 func ShowUpsell() error {
+	if show, err := claimUpsell(); !show {
+		return err
+	}
+
+	// Show the upsell message
+	showRandomUpsell()
+	return nil
+}
+
+// claimUpsell reports whether a banner should be shown now and, if so,
+// records that it was
+func claimUpsell() (bool, error) {
+	if UpsellDisabled {
+		return false, nil
+	}
+
 	// Check if user is already on paid plan
 	token, err := config.LoadToken()
 	if err == nil && token != "" {
-		return nil // Already paid user
+		return false, nil // Already paid user
 	}
 
 	// Check if we should show upsell
 	shouldShow, err := config.ShouldShowUpsell()
-	if err != nil {
-		return err
-	}
-
-	if !shouldShow {
-		return nil
+	if err != nil || !shouldShow {
+		return false, err
 	}
 
 	// Mark that we've shown the upsell
 	if err := config.MarkUpsellShown(); err != nil {
-		return err
+		return false, err
 	}
-
-	// Show the upsell message
-	showRandomUpsell()
-	return nil
+	return true, nil
 }
 
 // showRandomUpsell displays a randomized upsell message
@@ -121,34 +139,17 @@ func showRandomUpsell() {
 	cta := UpsellCallToAction[rand.Intn(len(UpsellCallToAction))]
 
 	// Display the upsell
-	fmt.Println()
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("💡 %s\n", message.Message)
-	fmt.Printf("   %s\n", cta)
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
+	fmt.Fprintln(UpsellOutput)
+	fmt.Fprintln(UpsellOutput, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(UpsellOutput, "💡 %s\n", message.Message)
+	fmt.Fprintf(UpsellOutput, "   %s\n", cta)
+	fmt.Fprintln(UpsellOutput, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(UpsellOutput)
 }
 
 // ShowContextualUpsell shows a contextual upsell based on the command being used
 func ShowContextualUpsell(command string) error {
-	// Check if user is already on paid plan
-	token, err := config.LoadToken()
-	if err == nil && token != "" {
-		return nil // Already paid user
-	}
-
-	// Check if we should show upsell
-	shouldShow, err := config.ShouldShowUpsell()
-	if err != nil {
-		return err
-	}
-
-	if !shouldShow {
-		return nil
-	}
-
-	// Mark that we've shown the upsell
-	if err := config.MarkUpsellShown(); err != nil {
+	if show, err := claimUpsell(); !show {
 		return err
 	}
 
@@ -195,34 +196,17 @@ func showContextualUpsell(command string) {
 	cta := UpsellCallToAction[rand.Intn(len(UpsellCallToAction))]
 
 	// Display the contextual upsell
-	fmt.Println()
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("💡 %s\n", message)
-	fmt.Printf("   %s\n", cta)
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
+	fmt.Fprintln(UpsellOutput)
+	fmt.Fprintln(UpsellOutput, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(UpsellOutput, "💡 %s\n", message)
+	fmt.Fprintf(UpsellOutput, "   %s\n", cta)
+	fmt.Fprintln(UpsellOutput, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(UpsellOutput)
 }
 
 // ShowFeatureUpsell shows a specific feature-focused upsell
 func ShowFeatureUpsell(feature string) error {
-	// Check if user is already on paid plan
-	token, err := config.LoadToken()
-	if err == nil && token != "" {
-		return nil // Already paid user
-	}
-
-	// Check if we should show upsell
-	shouldShow, err := config.ShouldShowUpsell()
-	if err != nil {
-		return err
-	}
-
-	if !shouldShow {
-		return nil
-	}
-
-	// Mark that we've shown the upsell
-	if err := config.MarkUpsellShown(); err != nil {
+	if show, err := claimUpsell(); !show {
 		return err
 	}
 
@@ -272,10 +256,10 @@ func showFeatureUpsell(feature string) {
 	cta := UpsellCallToAction[rand.Intn(len(UpsellCallToAction))]
 
 	// Display the feature-specific upsell
-	fmt.Println()
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("💡 %s\n", message)
-	fmt.Printf("   %s\n", cta)
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
+	fmt.Fprintln(UpsellOutput)
+	fmt.Fprintln(UpsellOutput, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(UpsellOutput, "💡 %s\n", message)
+	fmt.Fprintf(UpsellOutput, "   %s\n", cta)
+	fmt.Fprintln(UpsellOutput, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(UpsellOutput)
 }
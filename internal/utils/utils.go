@@ -3,33 +3,9 @@ package utils
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
-// GetPassphrase retrieves the passphrase from flags or prompts user
-func GetPassphrase(pass, passFile string) (string, error) {
-	if pass != "" {
-		return pass, nil
-	}
-
-	if passFile != "" {
-		data, err := os.ReadFile(passFile)
-		if err != nil {
-			return "", fmt.Errorf("failed to read passphrase file: %v", err)
-		}
-		// Remove trailing newline if present
-		if len(data) > 0 && data[len(data)-1] == '\n' {
-			data = data[:len(data)-1]
-		}
-		return string(data), nil
-	}
-
-	// Prompt user for passphrase
-	fmt.Print("Enter passphrase: ")
-	var passphrase string
-	fmt.Scanln(&passphrase)
-	return passphrase, nil
-}
-
 // GetAPIURL returns the API URL from environment variable or default
 func GetAPIURL() string {
 	if url := os.Getenv("DEV_SECRETSNAP_API_URL"); url != "" {
@@ -37,3 +13,13 @@ func GetAPIURL() string {
 	}
 	return "https://api.secretsnap.dev"
 }
+
+// Confirm asks a yes/no question on stderr and reports whether the answer
+// was yes. Anything other than y or yes, including no input, is a no.
+func Confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+	var answer string
+	fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
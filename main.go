@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"secretsnap/cmd"
@@ -23,8 +22,5 @@ func main() {
 	// Initialize commands
 	cmd.InitCommands(rootCmd)
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	os.Exit(cmd.Execute(rootCmd))
 }
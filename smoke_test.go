@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"secretsnap/internal/api"
+	"secretsnap/internal/config"
 )
 
 // SmokeTestData holds test configuration and state
@@ -165,8 +166,7 @@ func TestSmokeLocalMode(t *testing.T) {
 		}
 
 		// Check keys.json was created with correct permissions
-		homeDir, _ := os.UserHomeDir()
-		keysFile := filepath.Join(homeDir, ".secretsnap", "keys.json")
+		keysFile := config.GetKeysConfigPath()
 		if _, err := os.Stat(keysFile); err == nil {
 			checkFilePermissions(t, keysFile, 0600)
 		}
@@ -354,8 +354,7 @@ func TestSmokeCloudMode(t *testing.T) {
 		}
 
 		// Check token file was created
-		homeDir, _ := os.UserHomeDir()
-		tokenFile := filepath.Join(homeDir, ".secretsnap", "token")
+		tokenFile := config.GetTokenPath()
 		if _, err := os.Stat(tokenFile); os.IsNotExist(err) {
 			t.Error("Token file not created")
 		}
@@ -455,8 +454,7 @@ func TestSmokeCloudMode(t *testing.T) {
 
 	t.Run("4_TokenExpiry", func(t *testing.T) {
 		// Corrupt token file
-		homeDir, _ := os.UserHomeDir()
-		tokenFile := filepath.Join(homeDir, ".secretsnap", "token")
+		tokenFile := config.GetTokenPath()
 		os.WriteFile(tokenFile, []byte("corrupted-token"), 0600)
 
 		// Try cloud command
@@ -537,8 +535,7 @@ func TestSmokeCloudModeRealLicense(t *testing.T) {
 		}
 
 		// Check token file was created
-		homeDir, _ := os.UserHomeDir()
-		tokenFile := filepath.Join(homeDir, ".secretsnap", "token")
+		tokenFile := config.GetTokenPath()
 		if _, err := os.Stat(tokenFile); os.IsNotExist(err) {
 			t.Error("Token file not created")
 		}
@@ -638,8 +635,7 @@ func TestSmokeCloudModeRealLicense(t *testing.T) {
 
 	t.Run("4_TokenExpiry", func(t *testing.T) {
 		// Corrupt token file
-		homeDir, _ := os.UserHomeDir()
-		tokenFile := filepath.Join(homeDir, ".secretsnap", "token")
+		tokenFile := config.GetTokenPath()
 		os.WriteFile(tokenFile, []byte("corrupted-token"), 0600)
 
 		// Try cloud command
@@ -920,8 +916,7 @@ func TestSmokeSecurity(t *testing.T) {
 		checkFilePermissions(t, outputFile, 0600)
 
 		// Test keys file permissions
-		homeDir, _ := os.UserHomeDir()
-		keysFile := filepath.Join(homeDir, ".secretsnap", "keys.json")
+		keysFile := config.GetKeysConfigPath()
 		if _, err := os.Stat(keysFile); err == nil {
 			checkFilePermissions(t, keysFile, 0600)
 		}
@@ -929,8 +924,7 @@ func TestSmokeSecurity(t *testing.T) {
 
 	t.Run("3_KeyLossScenarios", func(t *testing.T) {
 		// Test local key cache removal
-		homeDir, _ := os.UserHomeDir()
-		keysFile := filepath.Join(homeDir, ".secretsnap", "keys.json")
+		keysFile := config.GetKeysConfigPath()
 		originalKeys, _ := os.ReadFile(keysFile)
 		defer os.WriteFile(keysFile, originalKeys, 0600)
 